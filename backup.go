@@ -0,0 +1,263 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// backupChecksumsFile lists the SHA-256 checksum of every file of a backup in
+// the format of sha256sum.
+const backupChecksumsFile = "MANIFEST.sha256"
+
+// backupLocation is the directory or archive holding the backup of this run.
+// It is empty when nothing was backed up.
+var backupLocation string
+
+// backupChecksums maps the path of every file written to the backup, relative
+// to the backup directory, to its SHA-256 checksum.
+var backupChecksums = map[string]string{}
+
+// backupMu guards backupChecksums and backupLocation, which projects processed
+// concurrently write to.
+var backupMu sync.Mutex
+
+func backupRunDir() string {
+	return filepath.Join(backupDir, migrationRunID)
+}
+
+// backupProject writes the DeploymentConfigs and dependent objects of a
+// namespace, cleaned of server managed fields, to the backup of this run. It
+// must be called before anything in the namespace is changed.
+func backupProject(dcs []unstructured.Unstructured, dependents []dependentObject, namespace string) error {
+	for i := range dcs {
+		if err := backupObject(&dcs[i], namespace); err != nil {
+			return err
+		}
+	}
+	for _, dependent := range dependents {
+		if err := backupObject(dependent.Object, namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func backupObject(obj *unstructured.Unstructured, namespace string) error {
+	manifest := obj.DeepCopy()
+	cleanServerFields(manifest)
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("error marshaling %s %s to YAML: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	name := filepath.ToSlash(filepath.Join(namespace, fmt.Sprintf("%s-%s.yaml", strings.ToLower(obj.GetKind()), obj.GetName())))
+	path := filepath.Join(backupRunDir(), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating backup directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error writing backup of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	sum := sha256.Sum256(data)
+	backupMu.Lock()
+	defer backupMu.Unlock()
+	backupChecksums[name] = hex.EncodeToString(sum[:])
+	backupLocation = backupRunDir()
+	return nil
+}
+
+// finishBackup writes the checksum manifest of the backup and, with the
+// tar.gz format, packs the backup directory into a single archive.
+func finishBackup() error {
+	if backupLocation == "" {
+		return nil
+	}
+
+	var names []string
+	for name := range backupChecksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var manifest bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&manifest, "%s  %s\n", backupChecksums[name], name)
+	}
+	dir := backupRunDir()
+	if err := os.WriteFile(filepath.Join(dir, backupChecksumsFile), manifest.Bytes(), 0600); err != nil {
+		return fmt.Errorf("error writing backup manifest: %w", err)
+	}
+
+	if backupFormat != "tar.gz" {
+		return nil
+	}
+	archive := dir + ".tar.gz"
+	if err := writeBackupArchive(dir, archive, append(names, backupChecksumsFile)); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("error removing backup directory %s: %w", dir, err)
+	}
+	backupLocation = archive
+	return nil
+}
+
+func writeBackupArchive(dir, archive string, names []string) error {
+	f, err := os.Create(filepath.Clean(archive))
+	if err != nil {
+		return fmt.Errorf("error creating backup archive: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return fmt.Errorf("error reading backup file %s: %w", name, err)
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data))}); err != nil {
+			return fmt.Errorf("error writing backup archive: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("error writing backup archive: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("error writing backup archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("error writing backup archive: %w", err)
+	}
+	return f.Close()
+}
+
+// isBackupArchive reports whether path names a tar.gz backup.
+func isBackupArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// readBackup reads the objects of a backup directory or archive after
+// verifying its checksums. Paths that are not backups written by this tool are
+// read as plain manifests.
+func readBackup(path string) ([]*unstructured.Unstructured, error) {
+	if isBackupArchive(path) {
+		return readBackupArchive(path)
+	}
+
+	manifest, err := os.ReadFile(filepath.Join(path, backupChecksumsFile))
+	if err != nil {
+		return readManifests([]string{path})
+	}
+	files := map[string][]byte{}
+	checksums, err := parseBackupChecksums(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup %s: %w", path, err)
+	}
+	for name := range checksums {
+		data, err := os.ReadFile(filepath.Join(path, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup %s: %w", path, err)
+		}
+		files[name] = data
+	}
+	return decodeBackupFiles(path, files, checksums)
+}
+
+func readBackupArchive(path string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("error opening backup %s: %w", path, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup %s: %w", path, err)
+	}
+	tr := tar.NewReader(gz)
+	files := map[string][]byte{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup %s: %w", path, err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup %s: %w", path, err)
+		}
+		files[header.Name] = data
+	}
+
+	manifest, ok := files[backupChecksumsFile]
+	if !ok {
+		return nil, fmt.Errorf("backup %s has no %s", path, backupChecksumsFile)
+	}
+	checksums, err := parseBackupChecksums(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup %s: %w", path, err)
+	}
+	return decodeBackupFiles(path, files, checksums)
+}
+
+func parseBackupChecksums(manifest []byte) (map[string]string, error) {
+	checksums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(manifest))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		sum, name, ok := strings.Cut(line, "  ")
+		if !ok {
+			return nil, fmt.Errorf("invalid line in %s: %q", backupChecksumsFile, line)
+		}
+		checksums[name] = sum
+	}
+	return checksums, scanner.Err()
+}
+
+// decodeBackupFiles checks every file listed in the checksums and decodes the
+// objects they contain.
+func decodeBackupFiles(path string, files map[string][]byte, checksums map[string]string) ([]*unstructured.Unstructured, error) {
+	var names []string
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var objects []*unstructured.Unstructured
+	for _, name := range names {
+		data, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("backup %s is missing %s", path, name)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != checksums[name] {
+			return nil, fmt.Errorf("checksum mismatch for %s in backup %s", name, path)
+		}
+		objs, err := decodeManifests(bytes.NewReader(data), name)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, objs...)
+	}
+	return objects, nil
+}
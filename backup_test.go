@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func writeTestBackup(t *testing.T, format string) {
+	backupDir = t.TempDir()
+	backupFormat = format
+	backupLocation = ""
+	backupChecksums = map[string]string{}
+
+	dc := newHookedDC()
+	dc.SetResourceVersion("42")
+	service := dependentObject{
+		Resource: dependentResources[2],
+		Object: newDependent("v1", "Service", "web", map[string]interface{}{
+			"selector": map[string]interface{}{dcPodLabel: "test-dc"},
+		}),
+	}
+
+	assert.NoError(t, backupProject([]unstructured.Unstructured{*dc}, []dependentObject{service}, "test-namespace"))
+	assert.NoError(t, finishBackup())
+}
+
+func TestBackupDirectory(t *testing.T) {
+	writeTestBackup(t, "dir")
+
+	assert.Equal(t, filepath.Join(backupDir, migrationRunID), backupLocation)
+	assert.FileExists(t, filepath.Join(backupLocation, backupChecksumsFile))
+	assert.FileExists(t, filepath.Join(backupLocation, "test-namespace", "deploymentconfig-test-dc.yaml"))
+
+	objects, err := readBackup(backupLocation)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+	for _, obj := range objects {
+		assert.Empty(t, obj.GetResourceVersion())
+	}
+
+	backup, err := loadRollbackBackup(nil, migrationRunID)
+	assert.NoError(t, err)
+	assert.NotNil(t, backup.get("test-namespace", "DeploymentConfig", "test-dc"))
+	assert.Len(t, backup.dependentsOf("test-namespace", "test-dc"), 1)
+}
+
+func TestBackupArchive(t *testing.T) {
+	writeTestBackup(t, "tar.gz")
+
+	assert.Equal(t, filepath.Join(backupDir, migrationRunID+".tar.gz"), backupLocation)
+	assert.NoDirExists(t, filepath.Join(backupDir, migrationRunID))
+
+	objects, err := readBackup(backupLocation)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+}
+
+func TestReadBackupChecksumMismatch(t *testing.T) {
+	writeTestBackup(t, "dir")
+
+	path := filepath.Join(backupLocation, "test-namespace", "service-web.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"), 0600))
+
+	_, err := readBackup(backupLocation)
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
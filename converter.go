@@ -1,125 +1,437 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 func runConverter(cmd *cobra.Command, args []string) error {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if cutoverFinalAction != "pause" && cutoverFinalAction != "delete" {
+		return fmt.Errorf("invalid --cutover-final-action %q: must be pause or delete", cutoverFinalAction)
+	}
+	if validateMode != "none" && validateMode != "server" {
+		return fmt.Errorf("invalid --validate %q: must be none or server", validateMode)
+	}
+	if backupFormat != "dir" && backupFormat != "tar.gz" {
+		return fmt.Errorf("invalid --backup-format %q: must be dir or tar.gz", backupFormat)
+	}
+	if err := parseDCFilters(); err != nil {
+		return err
+	}
+	if err := checkReportFormats(reportFormats); err != nil {
+		return err
+	}
+	if _, ok := severityRank[Severity(failOn)]; !ok && failOn != "none" {
+		return fmt.Errorf("invalid --fail-on %q: must be none, info, warning or error", failOn)
+	}
+
+	if len(inputFiles) > 0 {
+		return runOfflineConverter(cmd)
+	}
+	if len(openShiftProjects) == 0 && !allProjects && projectSelector == "" {
+		return fmt.Errorf("either --projects, --all-projects, --project-selector or --from-file must be specified")
+	}
+
+	dynamicClient, err := connectToCluster()
 	if err != nil {
-		return fmt.Errorf("error building kubeconfig: %w", err)
+		return err
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
+	validProjects, err := resolveProjects(dynamicClient, openShiftProjects, allProjects, projectSelector)
 	if err != nil {
-		return fmt.Errorf("error creating Kubernetes clientset: %w", err)
+		return fmt.Errorf("error validating projects: %w", err)
 	}
+	recordProjectScope(validProjects)
+
+	if applyChanges || cutover {
+		logErr := logMessage(fmt.Sprintf("Migration run ID: %s", migrationRunID))
+		if logErr != nil {
+			fmt.Printf("Failed to log message: %v\n", logErr)
+		}
+		fmt.Printf("Migration run ID: %s (use it with 'rollback --run-id' to undo this run)\n", migrationRunID)
+	}
+
+	// Interrupting a cutover aborts it and restores the DeploymentConfig.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Perform preflight check
-	if err := preflightCheck(clientset); err != nil {
-		return fmt.Errorf("preflight check failed: %w", err)
+	projectErrs := make([]error, len(validProjects))
+	forEachConcurrently(len(validProjects), concurrency, func(i int) {
+		if err := processProject(ctx, dynamicClient, validProjects[i]); err != nil {
+			projectErrs[i] = fmt.Errorf("error processing project %s: %w", validProjects[i], err)
+		}
+	})
+	err = errors.Join(projectErrs...)
+
+	// The checksums are written even when a project failed so that whatever
+	// was changed up to that point can be rolled back.
+	if backupErr := finishBackup(); backupErr != nil {
+		return fmt.Errorf("error finishing backup: %w", backupErr)
+	}
+	if backupLocation != "" {
+		fmt.Printf("Original objects backed up to %s\n", backupLocation)
 	}
 
-	dynamicClient, err := dynamic.NewForConfig(config)
+	// The reports are written even when a project failed so that they
+	// account for every DeploymentConfig that was processed.
+	report := newReport()
+	if _, reportErr := writeReports(report, reportPath, reportFormats); reportErr != nil {
+		return errors.Join(err, fmt.Errorf("error generating reports: %w", reportErr))
+	}
 	if err != nil {
-		return fmt.Errorf("error creating dynamic client: %w", err)
+		return err
+	}
+
+	return checkFailOn(cmd, report.Conversions)
+}
+
+// runOfflineConverter converts the DeploymentConfigs found in the --from-file
+// inputs without contacting a cluster.
+func runOfflineConverter(cmd *cobra.Command) error {
+	if applyChanges || cutover || validateMode == "server" {
+		return fmt.Errorf("--apply-changes, --cutover and --validate=server cannot be used with --from-file")
 	}
 
-	validProjects, err := validateProjects(dynamicClient, openShiftProjects)
+	objects, err := readManifests(inputFiles)
 	if err != nil {
-		return fmt.Errorf("error validating projects: %w", err)
+		return fmt.Errorf("error reading input manifests: %w", err)
+	}
+
+	// Selectors are repaired against every DeploymentConfig of the namespace,
+	// including the ones that are filtered out.
+	siblings := map[string]podLabelIndex{}
+	for _, obj := range objects {
+		if !isDeploymentConfig(obj) {
+			continue
+		}
+		namespace := offlineNamespace(obj)
+		if siblings[namespace] == nil {
+			siblings[namespace] = podLabelIndex{}
+		}
+		siblings[namespace].add(obj)
 	}
 
-	for _, project := range validProjects {
-		if err := processProject(dynamicClient, project); err != nil {
-			return fmt.Errorf("error processing project %s: %w", project, err)
+	for _, obj := range objects {
+		namespace := offlineNamespace(obj)
+
+		if isTemplate(obj) {
+			if !processTemplate(obj, namespace) {
+				skippedInputObjects = append(skippedInputObjects, fmt.Sprintf("Template %s (no DeploymentConfigs)", obj.GetName()))
+			}
+			continue
+		}
+
+		if !isDeploymentConfig(obj) {
+			skippedInputObjects = append(skippedInputObjects, fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName()))
+			err := logMessage(fmt.Sprintf("Skipping %s %s: not a DeploymentConfig", obj.GetKind(), obj.GetName()))
+			if err != nil {
+				fmt.Printf("Failed to log message: %v\n", err)
+			}
+			continue
+		}
+
+		if reason := skipReason(obj); reason != "" {
+			recordSkippedDC(obj, namespace, reason)
+			continue
 		}
+		processDC(context.Background(), nil, obj, namespace, nil, siblings[namespace])
 	}
 
-	if err := generatePDFReport(reportPath); err != nil {
-		return fmt.Errorf("error generating PDF report: %w", err)
+	report := newReport()
+	if _, err := writeReports(report, reportPath, reportFormats); err != nil {
+		return fmt.Errorf("error generating reports: %w", err)
+	}
+
+	return checkFailOn(cmd, report.Conversions)
+}
+
+// offlineNamespace returns the namespace of an input object, or the default
+// one when it has none.
+func offlineNamespace(obj *unstructured.Unstructured) string {
+	if namespace := obj.GetNamespace(); namespace != "" {
+		return namespace
+	}
+	return offlineDefaultNamespace
+}
+
+func processProject(ctx context.Context, client dynamic.Interface, namespace string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logErr := logMessage(fmt.Sprintf("Panic occurred while processing project %s: %v", namespace, r))
+			if logErr != nil {
+				fmt.Printf("Failed to log message: %v\n", logErr)
+			}
+			err = fmt.Errorf("panic occurred while processing project %s: %v", namespace, r)
+		}
+	}()
+
+	dependents, err := listDependents(client, namespace)
+	if err != nil {
+		return fmt.Errorf("error getting dependent resources in project %s: %w", namespace, err)
+	}
+
+	siblings, err := indexPodLabels(client, namespace)
+	if err != nil {
+		return fmt.Errorf("error indexing pod labels in project %s: %w", namespace, err)
+	}
+
+	backup := applyChanges || cutover
+	if backup {
+		if err := backupProject(nil, dependents, namespace); err != nil {
+			return fmt.Errorf("error backing up project %s: %w", namespace, err)
+		}
+	}
+
+	err = getDCs(client, namespace, func(dcs []unstructured.Unstructured) error {
+		dcs = filterDCs(dcs, namespace)
+		// Each page is backed up before any of its DeploymentConfigs is changed.
+		if backup {
+			if err := backupProject(dcs, nil, namespace); err != nil {
+				return fmt.Errorf("error backing up project %s: %w", namespace, err)
+			}
+		}
+		forEachConcurrently(len(dcs), dcConcurrency, func(i int) {
+			processDC(ctx, client, &dcs[i], namespace, dependents, siblings)
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error processing DeploymentConfigs in project %s: %w", namespace, err)
+	}
+
+	templates, err := getTemplates(client, namespace)
+	if err != nil {
+		return fmt.Errorf("error getting Templates in project %s: %w", namespace, err)
+	}
+
+	for i := range templates.Items {
+		processTemplate(&templates.Items[i], namespace)
 	}
 
 	return nil
 }
 
-func processProject(client dynamic.Interface, namespace string) error {
+// processDC converts a single DeploymentConfig, rewrites the dependent objects
+// referencing it, writes the resulting manifests and records it for the
+// report. The client is nil in offline mode, in which case nothing is looked
+// up in or applied to the cluster. The selector is repaired against the pod
+// labels of the siblings.
+func processDC(ctx context.Context, client dynamic.Interface, dc *unstructured.Unstructured, namespace string, dependents []dependentObject, siblings podLabelIndex) {
+	var conversionInfo ConversionInfo
 	defer func() {
 		if r := recover(); r != nil {
-			err := logMessage(fmt.Sprintf("Panic occurred while processing project %s: %v", namespace, r))
+			err := logMessage(fmt.Sprintf("Panic occurred while processing DeploymentConfig %s in project %s: %v", dc.GetName(), namespace, r))
 			if err != nil {
 				fmt.Printf("Failed to log message: %v\n", err)
 			}
+			if conversionInfo.DeploymentConfigName == "" {
+				conversionInfo = ConversionInfo{
+					Timestamp:            time.Now().Format(time.RFC3339),
+					Namespace:            namespace,
+					DeploymentConfigName: dc.GetName(),
+				}
+			}
+			conversionInfo.Outcome = OutcomePanicked
+			conversionInfo.Error = fmt.Sprint(r)
+			recordConversionInfos(conversionInfo)
 		}
 	}()
 
-	dcList, err := getDCs(client, namespace)
+	conversionInfo = newConversionInfo(dc, namespace, siblings)
+
+	deployment, err := convertDCtoDeployment(dc, siblings)
 	if err != nil {
-		return fmt.Errorf("error getting DeploymentConfigs in project %s: %w", namespace, err)
+		recordFailedConversion(conversionInfo, fmt.Errorf("error converting DeploymentConfig: %w", err))
+		return
 	}
 
-	for _, dc := range dcList.Items {
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					err := logMessage(fmt.Sprintf("Panic occurred while processing DeploymentConfig %s in project %s: %v", dc.GetName(), namespace, r))
-					if err != nil {
-						fmt.Printf("Failed to log message: %v\n", err)
-					}
-				}
-			}()
-
-			conversionInfo := ConversionInfo{
-				Timestamp:            time.Now().Format(time.RFC3339),
-				Namespace:            namespace,
-				DeploymentConfigName: dc.GetName(),
-				HasTriggers:          hasTriggers(&dc),
-				HasLifecycleHooks:    hasLifecycleHooks(&dc),
-				HasAutoRollbacks:     hasAutoRollbacks(&dc),
-				UsesCustomStrategies: usesCustomStrategies(&dc),
+	imageResolutions, err := resolveImages(client, dc, deployment)
+	if err != nil {
+		recordFailedConversion(conversionInfo, fmt.Errorf("error resolving images: %w", err))
+		return
+	}
+	conversionInfo.ImageResolutions = imageResolutions
+	conversionInfo.Findings = append(conversionInfo.Findings, unresolvedImages(imageResolutions)...)
+	conversionInfo.Findings = append(conversionInfo.Findings, unpinnedImages(imageResolutions)...)
+
+	hookJobs, hookNotes, err := convertHooksToJobs(dc, deployment)
+	if err != nil {
+		recordFailedConversion(conversionInfo, fmt.Errorf("error converting lifecycle hooks: %w", err))
+		return
+	}
+	conversionInfo.Findings = append(conversionInfo.Findings, hookNotes...)
+
+	if client != nil {
+		overlaps, err := selectorOverlaps(client, deployment)
+		if err != nil {
+			logErr := logMessage(fmt.Sprintf("Error checking selector overlaps of Deployment %s in project %s: %v", deployment.GetName(), namespace, err))
+			if logErr != nil {
+				fmt.Printf("Failed to log message: %v\n", logErr)
 			}
+		}
+		conversionInfo.Findings = append(conversionInfo.Findings, overlaps...)
+	}
 
-			deployment, err := convertDCtoDeployment(&dc)
-			if err != nil {
-				logErr := logMessage(fmt.Sprintf("Error converting DeploymentConfig %s in project %s: %v", dc.GetName(), namespace, err))
-				if logErr != nil {
-					fmt.Printf("Failed to log message: %v\n", logErr)
-				}
+	conversionInfo.DeploymentManifest = manifestYAML(deployment)
+	if problems := validateDeployment(deployment); len(problems) > 0 {
+		conversionInfo.Findings = append(conversionInfo.Findings, problems...)
+		if !allowInvalid {
+			recordFailedConversion(conversionInfo, errors.New("converted Deployment failed schema validation and was not saved; rerun with --allow-invalid to save it"))
+			return
+		}
+	}
+
+	if err := saveManifestYAML(deployment, namespace); err != nil {
+		recordFailedConversion(conversionInfo, fmt.Errorf("error saving Deployment YAML for %s: %w", deployment.GetName(), err))
+		return
+	}
+
+	for _, job := range hookJobs {
+		if err := saveManifestYAML(job, namespace); err != nil {
+			recordFailedConversion(conversionInfo, fmt.Errorf("error saving hook Job YAML for %s: %w", job.GetName(), err))
+			return
+		}
+	}
+
+	// Several DCs can reference the same dependent object.
+	dependentsMu.Lock()
+	rewrittenDependents, dependentRewrites := rewriteDependents(dependents, dc, deployment)
+	dependentsMu.Unlock()
+	for _, dependent := range rewrittenDependents {
+		if err := saveDependentYAML(dependent, namespace); err != nil {
+			recordFailedConversion(conversionInfo, fmt.Errorf("error saving %s YAML for %s: %w", dependent.Resource.Kind, dependent.Object.GetName(), err))
+			return
+		}
+	}
+	conversionInfo.DependentRewrites = dependentRewrites
+
+	if validateMode == "server" && client != nil {
+		conversionInfo.Validated = true
+		conversionInfo.Findings = append(conversionInfo.Findings, validateOnServer(deployment)...)
+	}
+
+	if cutover && client != nil {
+		if err := checkNotPaused(dc); err != nil {
+			recordFailedConversion(conversionInfo, fmt.Errorf("cutover not started: %w", err))
+			return
+		}
+		steps, outcome, err := runCutover(ctx, client, dc, deployment, rewrittenDependents, dependentRewrites)
+		conversionInfo.CutoverSteps = steps
+		conversionInfo.ApplyOutcome = outcome
+		if err != nil {
+			if outcome == ApplyConflicted {
+				conversionInfo.Findings = append(conversionInfo.Findings, newFinding(codeApplyConflict, "metadata.managedFields", err.Error()))
+			}
+			recordFailedConversion(conversionInfo, fmt.Errorf("error cutting over: %w", err))
+			return
+		}
+		conversionInfo.Outcome = OutcomeApplied
+	} else if applyChanges && client != nil {
+		if len(rewrittenDependents) > 0 {
+			if err := checkNotPaused(dc); err != nil {
+				recordFailedConversion(conversionInfo, fmt.Errorf("Deployment %s was not applied: %w", deployment.GetName(), err))
 				return
 			}
-
-			if err := saveDeploymentYAML(deployment, namespace); err != nil {
-				logErr := logMessage(fmt.Sprintf("Error saving Deployment YAML for %s in project %s: %v", deployment.GetName(), namespace, err))
+		}
+		outcome, err := applyDeployment(client, deployment)
+		conversionInfo.ApplyOutcome = outcome
+		if err != nil {
+			if outcome == ApplyConflicted {
+				conversionInfo.Findings = append(conversionInfo.Findings, newFinding(codeApplyConflict, "metadata.managedFields", err.Error()))
+			}
+			recordFailedConversion(conversionInfo, fmt.Errorf("error applying Deployment %s: %w", deployment.GetName(), err))
+			return
+		}
+		// Switching a Service before the Deployment has pods would leave it
+		// without endpoints when its new selector does not match the DC pods.
+		if len(rewrittenDependents) > 0 {
+			if err := waitForAvailable(ctx, client, deploymentRes, namespace, deployment.GetName(), cutoverTimeout); err != nil {
+				recordFailedConversion(conversionInfo, fmt.Errorf("dependent resources were not switched to the Deployment: %w", err))
+				return
+			}
+		}
+		for i, dependent := range rewrittenDependents {
+			if _, err := applyDependent(client, dependent, dc, deployment); err != nil {
+				logErr := logMessage(fmt.Sprintf("Error applying %s %s in project %s: %v", dependent.Resource.Kind, dependent.Object.GetName(), namespace, err))
 				if logErr != nil {
 					fmt.Printf("Failed to log message: %v\n", logErr)
 				}
-				return
+				continue
 			}
+			dependentRewrites[i].Applied = true
+		}
+		conversionInfo.Outcome = OutcomeApplied
+	} else {
+		conversionInfo.Outcome = OutcomeConverted
+	}
 
-			if applyChanges {
-				if err := applyDeployment(client, deployment); err != nil {
-					logErr := logMessage(fmt.Sprintf("Error applying Deployment %s in project %s: %v", deployment.GetName(), namespace, err))
-					if logErr != nil {
-						fmt.Printf("Failed to log message: %v\n", logErr)
-					}
-				}
-			}
+	recordConversionInfos(conversionInfo)
+}
 
-			conversionInfos = append(conversionInfos, conversionInfo)
-		}()
+// recordFailedConversion logs why a DeploymentConfig could not be migrated and
+// records it for the report.
+func recordFailedConversion(info ConversionInfo, err error) {
+	logErr := logMessage(fmt.Sprintf("DeploymentConfig %s in project %s failed: %v", displayName(info), info.Namespace, err))
+	if logErr != nil {
+		fmt.Printf("Failed to log message: %v\n", logErr)
 	}
+	info.Outcome = OutcomeFailed
+	info.Error = err.Error()
+	recordConversionInfos(info)
+}
 
-	return nil
+// newConversionInfo records the features detected in a DeploymentConfig
+// before it is converted.
+func newConversionInfo(dc *unstructured.Unstructured, namespace string, siblings podLabelIndex) ConversionInfo {
+	conversionInfo := ConversionInfo{
+		Timestamp:            time.Now().Format(time.RFC3339),
+		Namespace:            namespace,
+		DeploymentConfigName: dc.GetName(),
+		SourceManifest:       manifestYAML(dc),
+	}
+	conversionInfo.Findings = detectFindings(dc, siblings)
+	conversionInfo.LifecycleHooks, _ = analyzeLifecycleHooks(dc)
+	return conversionInfo
+}
+
+// detectFindings runs the detectors that only need the DeploymentConfig.
+func detectFindings(dc *unstructured.Unstructured, siblings podLabelIndex) []Finding {
+	var findings []Finding
+	if hasTriggers(dc) {
+		findings = append(findings, newFinding(codeTriggers, "spec.triggers", "DeploymentConfig has ConfigChange or ImageChange triggers"))
+	}
+	findings = append(findings, manualImageTriggers(dc)...)
+	if hasAutoRollbacks(dc) {
+		findings = append(findings, newFinding(codeAutoRollback, "spec.strategy.rollingParams.autoRollbackEnabled", "Automatic rollback of failed rollouts is enabled"))
+	}
+	if usesCustomStrategies(dc) {
+		findings = append(findings, newFinding(codeCustomStrategy, "spec.strategy.customParams", "DeploymentConfig uses a Custom deployment strategy"))
+	}
+	for _, field := range unmappedSpecFields(dc) {
+		findings = append(findings, newFinding(codeUnmappedField, field, fmt.Sprintf("%s is not carried over to the Deployment", field)))
+	}
+	if spec, found, _ := unstructured.NestedMap(dc.Object, "spec"); found {
+		_, rolloutNotes := progressDeadline(spec)
+		findings = append(findings, rolloutNotes...)
+	}
+	findings = append(findings, analyzeSelector(dc, siblings)...)
+	_, hookWarnings := analyzeLifecycleHooks(dc)
+	findings = append(findings, hookWarnings...)
+	return findings
 }
 
-func convertDCtoDeployment(dc *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+// convertDCtoDeployment converts the DeploymentConfig, repairing its selector
+// against the pod labels of the siblings, which may be nil.
+func convertDCtoDeployment(dc *unstructured.Unstructured, siblings podLabelIndex) (*unstructured.Unstructured, error) {
 	deployment := &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"apiVersion": "apps/v1",
@@ -131,10 +443,14 @@ func convertDCtoDeployment(dc *unstructured.Unstructured) (*unstructured.Unstruc
 		return nil, fmt.Errorf("failed to copy metadata: %w", err)
 	}
 
-	if err := convertSpec(dc, deployment); err != nil {
+	if err := convertSpec(dc, deployment, siblings); err != nil {
 		return nil, fmt.Errorf("failed to convert spec: %w", err)
 	}
 
+	if err := setImageTriggers(dc, deployment); err != nil {
+		return nil, fmt.Errorf("failed to set image triggers: %w", err)
+	}
+
 	cleanupDeploymentConfig(deployment)
 
 	return deployment, nil
@@ -151,7 +467,9 @@ func copyMetadata(dc, deployment *unstructured.Unstructured) error {
 
 	newMetadata := make(map[string]interface{})
 	newMetadata["name"] = metadata["name"]
-	newMetadata["namespace"] = metadata["namespace"]
+	if namespace, ok := metadata["namespace"]; ok {
+		newMetadata["namespace"] = namespace
+	}
 
 	if preserveLabels {
 		if labels, ok := metadata["labels"].(map[string]interface{}); ok {
@@ -178,14 +496,15 @@ func copyMetadata(dc, deployment *unstructured.Unstructured) error {
 		}
 	}
 
-	newAnnotations["openshift.io/generated-by"] = "deploymentconfig-to-deployment-migration"
-	newAnnotations["openshift.io/migration-timestamp"] = time.Now().Format(time.RFC3339)
+	newAnnotations[generatedByAnnotation] = generatedByValue
+	newAnnotations[migrationTimestampAnnotation] = time.Now().Format(time.RFC3339)
+	newAnnotations[migrationRunIDAnnotation] = migrationRunID
 	newMetadata["annotations"] = newAnnotations
 
 	return unstructured.SetNestedMap(deployment.Object, newMetadata, "metadata")
 }
 
-func convertSpec(dc, deployment *unstructured.Unstructured) error {
+func convertSpec(dc, deployment *unstructured.Unstructured, siblings podLabelIndex) error {
 	spec, found, err := unstructured.NestedMap(dc.Object, "spec")
 	if err != nil {
 		return fmt.Errorf("error getting spec: %w", err)
@@ -206,14 +525,30 @@ func convertSpec(dc, deployment *unstructured.Unstructured) error {
 		return fmt.Errorf("failed to set template: %w", err)
 	}
 
+	if _, err := repairSelector(deployment, siblings); err != nil {
+		return fmt.Errorf("failed to repair selector: %w", err)
+	}
+
 	if err := setStrategy(spec, deployment); err != nil {
 		return fmt.Errorf("failed to set strategy: %w", err)
 	}
 
+	if err := setProgressDeadline(spec, deployment); err != nil {
+		return fmt.Errorf("failed to set progress deadline: %w", err)
+	}
+
+	if err := copySpecFields(spec, deployment); err != nil {
+		return fmt.Errorf("failed to copy spec fields: %w", err)
+	}
+
 	return nil
 }
 
 func setReplicas(spec map[string]interface{}, deployment *unstructured.Unstructured) error {
+	if ref, ok := spec["replicas"].(string); ok && isParameterReference(ref) {
+		return unstructured.SetNestedField(deployment.Object, ref, "spec", "replicas")
+	}
+
 	replicas, found, err := unstructured.NestedInt64(spec, "replicas")
 	if err != nil {
 		return fmt.Errorf("error getting replicas: %w", err)
@@ -230,7 +565,8 @@ func setSelector(spec map[string]interface{}, deployment *unstructured.Unstructu
 		return fmt.Errorf("error getting selector: %w", err)
 	}
 	if !found {
-		return fmt.Errorf("selector not found in DeploymentConfig spec")
+		// repairSelector fills in a selector.
+		selector = map[string]interface{}{}
 	}
 
 	delete(selector, "deploymentconfig")
@@ -290,8 +626,83 @@ func setStrategy(spec map[string]interface{}, deployment *unstructured.Unstructu
 	return unstructured.SetNestedMap(deployment.Object, deploymentStrategy, "spec", "strategy")
 }
 
+// defaultStrategyTimeoutSeconds is the rollout timeout the DC controller uses
+// when the strategy parameters do not set timeoutSeconds.
+const defaultStrategyTimeoutSeconds = 600
+
+func setProgressDeadline(spec map[string]interface{}, deployment *unstructured.Unstructured) error {
+	if ref, _ := strategyTimeoutReference(spec); ref != "" {
+		return unstructured.SetNestedField(deployment.Object, ref, "spec", "progressDeadlineSeconds")
+	}
+	deadline, _ := progressDeadline(spec)
+	return unstructured.SetNestedField(deployment.Object, deadline, "spec", "progressDeadlineSeconds")
+}
+
+// progressDeadline derives spec.progressDeadlineSeconds from the timeout of the
+// DeploymentConfig strategy and explains how the value was chosen. The DC
+// timeout bounds the whole rollout while the Deployment deadline bounds the
+// time without progress, so the translation only approximates the original
+// failure detection.
+func progressDeadline(spec map[string]interface{}) (int64, []Finding) {
+	strategyType, _, _ := unstructured.NestedString(spec, "strategy", "type")
+	if strategyType == "" {
+		strategyType = "Rolling"
+	}
+
+	var reasons []Finding
+	var params string
+	switch strategyType {
+	case "Rolling":
+		params = "rollingParams"
+		for _, field := range []string{"updatePeriodSeconds", "intervalSeconds"} {
+			if value, found, _ := unstructured.NestedInt64(spec, "strategy", params, field); found && value != 1 {
+				reasons = append(reasons, newFinding(codeRolloutTiming, "spec.strategy.rollingParams."+field,
+					fmt.Sprintf("strategy.rollingParams.%s=%d has no Deployment equivalent; the Deployment controller reacts to pod status changes instead of polling", field, value)).withSeverity(SeverityWarning))
+			}
+		}
+	case "Recreate":
+		params = "recreateParams"
+	}
+
+	if ref, params := strategyTimeoutReference(spec); ref != "" {
+		return defaultStrategyTimeoutSeconds, append(reasons, newFinding(codeRolloutTiming, "spec.progressDeadlineSeconds",
+			fmt.Sprintf("progressDeadlineSeconds set to template parameter %s from strategy.%s.timeoutSeconds", ref, params)))
+	}
+
+	deadline := int64(defaultStrategyTimeoutSeconds)
+	timeout, found, _ := unstructured.NestedInt64(spec, "strategy", params, "timeoutSeconds")
+	if params != "" && found && timeout > 0 {
+		deadline = timeout
+		reasons = append(reasons, newFinding(codeRolloutTiming, "spec.progressDeadlineSeconds",
+			fmt.Sprintf("progressDeadlineSeconds set to %d from strategy.%s.timeoutSeconds", deadline, params)))
+	} else {
+		reasons = append(reasons, newFinding(codeRolloutTiming, "spec.progressDeadlineSeconds",
+			fmt.Sprintf("progressDeadlineSeconds set to %d, the DC controller default timeout for the %s strategy", deadline, strategyType)))
+	}
+
+	minReadySeconds, _, _ := unstructured.NestedInt64(spec, "minReadySeconds")
+	if deadline <= minReadySeconds {
+		deadline += minReadySeconds
+		reasons = append(reasons, newFinding(codeRolloutTiming, "spec.progressDeadlineSeconds",
+			fmt.Sprintf("progressDeadlineSeconds raised to %d because it must exceed minReadySeconds (%d)", deadline, minReadySeconds)))
+	}
+
+	return deadline, reasons
+}
+
+// strategyTimeoutReference returns the template parameter reference used as
+// the strategy timeoutSeconds, if any, and the parameter block it was found in.
+func strategyTimeoutReference(spec map[string]interface{}) (string, string) {
+	for _, params := range []string{"rollingParams", "recreateParams"} {
+		value, found, _ := unstructured.NestedFieldNoCopy(spec, "strategy", params, "timeoutSeconds")
+		if ref, ok := value.(string); found && ok && isParameterReference(ref) {
+			return ref, params
+		}
+	}
+	return "", ""
+}
+
 func cleanupDeploymentConfig(deployment *unstructured.Unstructured) {
 	unstructured.RemoveNestedField(deployment.Object, "spec", "triggers")
 	unstructured.RemoveNestedField(deployment.Object, "spec", "test")
-	unstructured.RemoveNestedField(deployment.Object, "spec", "paused")
 }
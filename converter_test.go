@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 )
 
 func TestConvertDCtoDeployment(t *testing.T) {
@@ -42,7 +51,7 @@ func TestConvertDCtoDeployment(t *testing.T) {
 	}
 
 	// Convert DC to Deployment
-	deployment, err := convertDCtoDeployment(dc)
+	deployment, err := convertDCtoDeployment(dc, nil)
 
 	// Assert no error occurred
 	assert.NoError(t, err)
@@ -124,4 +133,171 @@ func TestCopyMetadata(t *testing.T) {
 	assert.Contains(t, annotations, "openshift.io/migration-timestamp")
 }
 
+func TestProgressDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     map[string]interface{}
+		expected int64
+		reasons  int
+	}{
+		{
+			name:     "Default timeout",
+			spec:     map[string]interface{}{},
+			expected: 600,
+			reasons:  1,
+		},
+		{
+			name: "Rolling timeout with polling parameters",
+			spec: map[string]interface{}{
+				"strategy": map[string]interface{}{
+					"type": "Rolling",
+					"rollingParams": map[string]interface{}{
+						"timeoutSeconds":      int64(300),
+						"updatePeriodSeconds": int64(5),
+						"intervalSeconds":     int64(1),
+					},
+				},
+			},
+			expected: 300,
+			reasons:  2,
+		},
+		{
+			name: "Recreate timeout below minReadySeconds",
+			spec: map[string]interface{}{
+				"minReadySeconds": int64(120),
+				"strategy": map[string]interface{}{
+					"type": "Recreate",
+					"recreateParams": map[string]interface{}{
+						"timeoutSeconds": int64(60),
+					},
+				},
+			},
+			expected: 180,
+			reasons:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadline, reasons := progressDeadline(tt.spec)
+			assert.Equal(t, tt.expected, deadline)
+			assert.Len(t, reasons, tt.reasons)
+		})
+	}
+}
+
 // Add more tests for other functions in converter.go
+
+func TestProcessProjectConcurrently(t *testing.T) {
+	out := t.TempDir()
+	outputDir = out
+	logFilePath = filepath.Join(out, "log.txt")
+	applyChanges, cutover, validateMode = false, false, "none"
+	dcConcurrency = 4
+	conversionInfos = nil
+	defer func() { dcConcurrency = 1 }()
+
+	listKinds := map[schema.GroupVersionResource]string{
+		deploymentConfigRes:      "DeploymentConfigList",
+		deploymentRes:            "DeploymentList",
+		templateRes:              "TemplateList",
+		imageStreamRes:           "ImageStreamList",
+		replicationControllerRes: "ReplicationControllerList",
+	}
+	for _, resource := range dependentResources {
+		listKinds[resource.GVR] = resource.Kind + "List"
+	}
+	var objects []runtime.Object
+	for i := 9; i >= 0; i-- {
+		dc := newHookedDC()
+		dc.SetName(fmt.Sprintf("dc-%d", i))
+		objects = append(objects, dc)
+	}
+	objects = append(objects, newDependent("v1", "Service", "web", map[string]interface{}{
+		"selector": map[string]interface{}{"deploymentconfig": "dc-3"},
+	}))
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+
+	assert.NoError(t, processProject(context.Background(), client, "test-namespace"))
+	assert.Len(t, conversionInfos, 10)
+
+	sortConversionInfos()
+	for i, info := range conversionInfos {
+		assert.Equal(t, fmt.Sprintf("dc-%d", i), info.DeploymentConfigName)
+		assert.Equal(t, OutcomeConverted, info.Outcome)
+	}
+	assert.Len(t, conversionInfos[3].DependentRewrites, 1)
+}
+
+func TestProcessDCRecordsFailure(t *testing.T) {
+	out := t.TempDir()
+	outputDir = out
+	logFilePath = filepath.Join(out, "log.txt")
+	conversionInfos = nil
+
+	dc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps.openshift.io/v1",
+		"kind":       "DeploymentConfig",
+		"spec":       map[string]interface{}{},
+	}}
+	processDC(context.Background(), nil, dc, "test-namespace", nil, nil)
+
+	assert.Len(t, conversionInfos, 1)
+	assert.Equal(t, OutcomeFailed, conversionInfos[0].Outcome)
+	assert.Contains(t, conversionInfos[0].Error, "metadata not found in DeploymentConfig")
+	logContent, err := os.ReadFile(logFilePath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(logContent), "failed: error converting DeploymentConfig")
+}
+
+func TestProcessDCWaitsBeforeSwitchingDependents(t *testing.T) {
+	setCutoverTestGlobals(t)
+	outputDir = t.TempDir()
+	applyChanges, cutover, validateMode = true, false, "none"
+	conversionInfos = nil
+	defer func() { applyChanges = false }()
+
+	client, dc := newCutoverClient(t, func() bool { return false })
+	service := newDependent("v1", "Service", "web", map[string]interface{}{
+		"selector": map[string]interface{}{"deploymentconfig": "test-dc"},
+	})
+	assert.NoError(t, client.Tracker().Add(service.DeepCopy()))
+	dependents := []dependentObject{{Resource: dependentResources[2], Object: service}}
+
+	processDC(context.Background(), client, dc, "test-namespace", dependents, nil)
+
+	assert.Len(t, conversionInfos, 1)
+	assert.Equal(t, OutcomeFailed, conversionInfos[0].Outcome)
+	assert.Contains(t, conversionInfos[0].Error, "dependent resources were not switched")
+	live, err := client.Resource(dependentResources[2].GVR).Namespace("test-namespace").Get(context.Background(), "web", metav1.GetOptions{})
+	assert.NoError(t, err)
+	selector, _, _ := unstructured.NestedStringMap(live.Object, "spec", "selector")
+	assert.Equal(t, map[string]string{"deploymentconfig": "test-dc"}, selector)
+}
+
+func TestProcessDCRefusesPausedDeploymentConfig(t *testing.T) {
+	setCutoverTestGlobals(t)
+	outputDir = t.TempDir()
+	defer func() { applyChanges, cutover = false, false }()
+
+	for _, mode := range []struct{ apply, cutover bool }{{false, true}, {true, false}} {
+		applyChanges, cutover, validateMode = mode.apply, mode.cutover, "none"
+		conversionInfos = nil
+
+		client, dc := newCutoverClient(t, func() bool { return true })
+		assert.NoError(t, unstructured.SetNestedField(dc.Object, true, "spec", "paused"))
+		service := newDependent("v1", "Service", "web", map[string]interface{}{
+			"selector": map[string]interface{}{"deploymentconfig": "test-dc"},
+		})
+		assert.NoError(t, client.Tracker().Add(service.DeepCopy()))
+		dependents := []dependentObject{{Resource: dependentResources[2], Object: service}}
+
+		processDC(context.Background(), client, dc, "test-namespace", dependents, nil)
+
+		assert.Len(t, conversionInfos, 1)
+		assert.Equal(t, OutcomeFailed, conversionInfos[0].Outcome)
+		assert.Contains(t, conversionInfos[0].Error, "DeploymentConfig test-dc is paused")
+		_, err := client.Tracker().Get(deploymentRes, "test-namespace", "test-dc")
+		assert.True(t, apierrors.IsNotFound(err))
+	}
+}
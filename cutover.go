@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+// cutoverPollInterval is how often the Deployment status is checked during a
+// cutover.
+var cutoverPollInterval = 5 * time.Second
+
+// CutoverStep records the outcome and duration of a single cutover step.
+type CutoverStep struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// runCutover moves a workload from the DeploymentConfig to the Deployment
+// without downtime: the Deployment is created and must become Available, the
+// dependent objects are switched over to the Deployment selector, the DC is
+// scaled down (stepwise when cutoverStep is set) and finally paused or
+// deleted. Switched Services keep selecting the DC pods only when the
+// Deployment selector uses labels they carry; otherwise traffic moves to the
+// Deployment pods at once and the stepwise scale-down only limits how fast DC
+// capacity goes away. If any step fails or ctx is cancelled, the DC is scaled
+// back to its original replica count, the dependent objects already switched
+// are restored and a Deployment created by the cutover is deleted. The outcome of applying the Deployment is returned
+// alongside the steps.
+func runCutover(ctx context.Context, client dynamic.Interface, dc, deployment *unstructured.Unstructured, dependents []dependentObject, rewrites []DependentRewrite) ([]CutoverStep, ApplyOutcome, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", fmt.Errorf("cutover not started: %w", err)
+	}
+
+	namespace := dc.GetNamespace()
+	originalReplicas, found, err := unstructured.NestedInt64(dc.Object, "spec", "replicas")
+	if err != nil {
+		return nil, "", fmt.Errorf("error getting replicas of DeploymentConfig %s: %w", dc.GetName(), err)
+	}
+	if !found {
+		originalReplicas = 1
+	}
+
+	var steps []CutoverStep
+	var outcome ApplyOutcome
+	scaledDown := false
+	// originals holds the switched dependents as they were before, by index.
+	originals := map[int]*unstructured.Unstructured{}
+	step := func(name string, fn func() error) error {
+		start := time.Now()
+		err := fn()
+		if err == nil {
+			err = ctx.Err()
+		}
+		cutoverStep := CutoverStep{Name: name, Duration: time.Since(start)}
+		if err != nil {
+			cutoverStep.Error = err.Error()
+		}
+		steps = append(steps, cutoverStep)
+		logCutoverStep(namespace, dc.GetName(), cutoverStep)
+		return err
+	}
+
+	abort := func(cause error) ([]CutoverStep, ApplyOutcome, error) {
+		var failures []string
+		// The restores must run even when ctx was cancelled.
+		if scaledDown {
+			restoreErr := step(fmt.Sprintf("restore DeploymentConfig to %d replicas", originalReplicas), func() error {
+				return scaleDeploymentConfig(context.Background(), client, namespace, dc.GetName(), originalReplicas)
+			})
+			if restoreErr != nil {
+				failures = append(failures, fmt.Sprintf("restoring DeploymentConfig replicas failed: %v", restoreErr))
+			}
+		}
+		if len(originals) > 0 {
+			restoreErr := step("restore dependent resources", func() error {
+				var errs []error
+				for i := range dependents {
+					original, ok := originals[i]
+					if !ok {
+						continue
+					}
+					if err := restoreDependent(client, dependents[i].Resource, original); err != nil {
+						errs = append(errs, err)
+						continue
+					}
+					rewrites[i].Applied = false
+				}
+				return errors.Join(errs...)
+			})
+			if restoreErr != nil {
+				failures = append(failures, fmt.Sprintf("restoring dependent resources failed: %v", restoreErr))
+			}
+		}
+		// A Deployment this cutover created did not exist before, so it is
+		// removed rather than left running next to the DC pods.
+		if outcome == ApplyCreated {
+			deleteErr := step("delete Deployment", func() error {
+				return client.Resource(deploymentRes).Namespace(namespace).Delete(context.Background(), deployment.GetName(), metav1.DeleteOptions{})
+			})
+			if deleteErr != nil {
+				failures = append(failures, fmt.Sprintf("deleting Deployment failed: %v", deleteErr))
+			}
+		}
+		if len(failures) > 0 {
+			return steps, outcome, fmt.Errorf("cutover aborted: %w (%s)", cause, strings.Join(failures, "; "))
+		}
+		return steps, outcome, fmt.Errorf("cutover aborted: %w", cause)
+	}
+
+	if err := step("apply Deployment", func() error {
+		var err error
+		outcome, err = applyDeployment(client, deployment)
+		return err
+	}); err != nil {
+		return abort(err)
+	}
+
+	if err := step("wait for Deployment to become Available", func() error {
+		return waitForAvailable(ctx, client, deploymentRes, namespace, deployment.GetName(), cutoverTimeout)
+	}); err != nil {
+		return abort(err)
+	}
+
+	if err := step("switch dependent resources to the Deployment", func() error {
+		for i, dependent := range dependents {
+			original, err := applyDependent(client, dependent, dc, deployment)
+			if err != nil {
+				return err
+			}
+			if original != nil {
+				originals[i] = original
+			}
+			rewrites[i].Applied = true
+		}
+		return nil
+	}); err != nil {
+		return abort(err)
+	}
+
+	for _, replicas := range cutoverScaleSteps(originalReplicas, cutoverStep) {
+		scaledDown = true
+		if err := step(fmt.Sprintf("scale DeploymentConfig to %d replicas", replicas), func() error {
+			if err := scaleDeploymentConfig(ctx, client, namespace, dc.GetName(), replicas); err != nil {
+				return err
+			}
+			return waitForAvailable(ctx, client, deploymentRes, namespace, deployment.GetName(), cutoverTimeout)
+		}); err != nil {
+			return abort(err)
+		}
+	}
+
+	switch cutoverFinalAction {
+	case "delete":
+		if err := step("delete DeploymentConfig", func() error {
+			return client.Resource(deploymentConfigRes).Namespace(namespace).Delete(ctx, dc.GetName(), metav1.DeleteOptions{})
+		}); err != nil {
+			return abort(err)
+		}
+	default:
+		if err := step("pause DeploymentConfig", func() error {
+			return patchSpec(ctx, client, deploymentConfigRes, namespace, dc.GetName(), map[string]interface{}{"paused": true})
+		}); err != nil {
+			return abort(err)
+		}
+	}
+
+	return steps, outcome, nil
+}
+
+// checkNotPaused refuses to roll out a paused DeploymentConfig where the
+// Deployment has to become Available: the paused field is carried over, so the
+// Deployment would never start its pods.
+func checkNotPaused(dc *unstructured.Unstructured) error {
+	paused, _, err := unstructured.NestedBool(dc.Object, "spec", "paused")
+	if err != nil {
+		return fmt.Errorf("error getting paused of DeploymentConfig %s: %w", dc.GetName(), err)
+	}
+	if paused {
+		return fmt.Errorf("DeploymentConfig %s is paused, so its Deployment would never become Available; resume the DeploymentConfig first", dc.GetName())
+	}
+	return nil
+}
+
+// cutoverScaleSteps returns the successive DC replica counts when scaling down
+// by step replicas at a time. A step of zero or less scales down at once.
+func cutoverScaleSteps(replicas, step int64) []int64 {
+	if step <= 0 || step >= replicas {
+		return []int64{0}
+	}
+	var steps []int64
+	for replicas > 0 {
+		replicas -= step
+		if replicas < 0 {
+			replicas = 0
+		}
+		steps = append(steps, replicas)
+	}
+	return steps
+}
+
+// waitForAvailable polls a Deployment or DeploymentConfig until it is
+// available or the timeout expires.
+func waitForAvailable(ctx context.Context, client dynamic.Interface, resource schema.GroupVersionResource, namespace, name string, timeout time.Duration) error {
+	err := wait.PollUntilContextTimeout(ctx, cutoverPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		obj, err := client.Resource(resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return isAvailable(obj), nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s %s did not become available: %w", resource.Resource, name, err)
+	}
+	return nil
+}
+
+// isAvailable reports whether a Deployment or DeploymentConfig has rolled out
+// its current generation and all desired replicas are available.
+func isAvailable(obj *unstructured.Unstructured) bool {
+	generation := obj.GetGeneration()
+	observedGeneration, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if observedGeneration < generation {
+		return false
+	}
+
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	availableReplicas, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	if availableReplicas < replicas {
+		return false
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]interface{}); ok && condition["type"] == "Available" {
+			return condition["status"] == "True"
+		}
+	}
+	return false
+}
+
+func scaleDeploymentConfig(ctx context.Context, client dynamic.Interface, namespace, name string, replicas int64) error {
+	return patchSpec(ctx, client, deploymentConfigRes, namespace, name, map[string]interface{}{"replicas": replicas})
+}
+
+// patchSpec merge patches the given fields into the spec of an object.
+func patchSpec(ctx context.Context, client dynamic.Interface, resource schema.GroupVersionResource, namespace, name string, spec map[string]interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{"spec": spec})
+	if err != nil {
+		return fmt.Errorf("error marshaling patch: %w", err)
+	}
+	_, err = client.Resource(resource).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("error patching %s %s in namespace %s: %w", resource.Resource, name, namespace, err)
+	}
+	return nil
+}
+
+func logCutoverStep(namespace, name string, step CutoverStep) {
+	message := fmt.Sprintf("Cutover of DeploymentConfig %s in project %s: %s completed in %s", name, namespace, step.Name, step.Duration.Round(time.Millisecond))
+	if step.Error != "" {
+		message = fmt.Sprintf("Cutover of DeploymentConfig %s in project %s: %s failed after %s: %s", name, namespace, step.Name, step.Duration.Round(time.Millisecond), step.Error)
+	}
+	fmt.Println(message)
+	if err := logMessage(message); err != nil {
+		fmt.Printf("Failed to log message: %v\n", err)
+	}
+}
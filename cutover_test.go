@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newCutoverClient(t *testing.T, available func() bool) (*dynamicfake.FakeDynamicClient, *unstructured.Unstructured) {
+	dc := newTestDC(withField(int64(3), "spec", "replicas"))
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		deploymentConfigRes: "DeploymentConfigList",
+		deploymentRes:       "DeploymentList",
+	}, dc.DeepCopy())
+	addApplyReactor(client, deploymentRes)
+	client.PrependReactor("get", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		// Until the Deployment is applied the tracker reports it missing.
+		if _, err := client.Tracker().Get(deploymentRes, "test-namespace", "test-dc"); err != nil {
+			return false, nil, nil
+		}
+		status := "False"
+		if available() {
+			status = "True"
+		}
+		return true, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "test-dc", "namespace": "test-namespace"},
+				"spec":       map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{
+					"availableReplicas": int64(3),
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Available", "status": status},
+					},
+				},
+			},
+		}, nil
+	})
+	return client, dc
+}
+
+func setCutoverTestGlobals(t *testing.T) {
+	logFilePath = filepath.Join(t.TempDir(), "log.txt")
+	cutoverPollInterval = time.Millisecond
+	cutoverTimeout = 50 * time.Millisecond
+	cutoverStep = 2
+	cutoverFinalAction = "pause"
+}
+
+func TestRunCutover(t *testing.T) {
+	setCutoverTestGlobals(t)
+	client, dc := newCutoverClient(t, func() bool { return true })
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	steps, outcome, err := runCutover(context.Background(), client, dc, deployment, nil, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, ApplyConflicted, outcome)
+
+	var names []string
+	for _, step := range steps {
+		names = append(names, step.Name)
+		assert.Empty(t, step.Error)
+	}
+	assert.Equal(t, []string{
+		"apply Deployment",
+		"wait for Deployment to become Available",
+		"switch dependent resources to the Deployment",
+		"scale DeploymentConfig to 1 replicas",
+		"scale DeploymentConfig to 0 replicas",
+		"pause DeploymentConfig",
+	}, names)
+
+	live, err := client.Resource(deploymentConfigRes).Namespace("test-namespace").Get(context.Background(), "test-dc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	replicas, _, _ := unstructured.NestedInt64(live.Object, "spec", "replicas")
+	assert.Equal(t, int64(0), replicas)
+	paused, _, _ := unstructured.NestedBool(live.Object, "spec", "paused")
+	assert.True(t, paused)
+}
+
+func TestRunCutoverRestoresReplicasOnFailure(t *testing.T) {
+	setCutoverTestGlobals(t)
+	checks := 0
+	client, dc := newCutoverClient(t, func() bool {
+		checks++
+		return checks <= 1
+	})
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	steps, outcome, err := runCutover(context.Background(), client, dc, deployment, nil, nil)
+	assert.Error(t, err)
+	assert.Equal(t, ApplyCreated, outcome)
+
+	var names []string
+	for _, step := range steps {
+		names = append(names, step.Name)
+	}
+	assert.Equal(t, "restore DeploymentConfig to 3 replicas", names[len(names)-2])
+	assert.Equal(t, "delete Deployment", names[len(names)-1])
+	assert.Empty(t, steps[len(steps)-1].Error)
+
+	live, err := client.Resource(deploymentConfigRes).Namespace("test-namespace").Get(context.Background(), "test-dc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	replicas, _, _ := unstructured.NestedInt64(live.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+	_, err = client.Tracker().Get(deploymentRes, "test-namespace", "test-dc")
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestRunCutoverCancelled(t *testing.T) {
+	setCutoverTestGlobals(t)
+	client, dc := newCutoverClient(t, func() bool { return true })
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	steps, _, err := runCutover(ctx, client, dc, deployment, nil, nil)
+	assert.Error(t, err)
+	assert.Empty(t, steps)
+}
+
+// addCutoverService adds a Service selecting the DC pods to the cluster and
+// returns it rewritten for the Deployment.
+func addCutoverService(t *testing.T, client *dynamicfake.FakeDynamicClient, dc, deployment *unstructured.Unstructured) ([]dependentObject, []DependentRewrite) {
+	service := newDependent("v1", "Service", "web", map[string]interface{}{
+		"selector": map[string]interface{}{"deploymentconfig": "test-dc"},
+	})
+	assert.NoError(t, client.Tracker().Add(service.DeepCopy()))
+	dependents, rewrites := rewriteDependents([]dependentObject{{Resource: dependentResources[2], Object: service}}, dc, deployment)
+	assert.Len(t, dependents, 1)
+	return dependents, rewrites
+}
+
+func liveServiceSelector(t *testing.T, client *dynamicfake.FakeDynamicClient) map[string]string {
+	live, err := client.Resource(dependentResources[2].GVR).Namespace("test-namespace").Get(context.Background(), "web", metav1.GetOptions{})
+	assert.NoError(t, err)
+	selector, _, _ := unstructured.NestedStringMap(live.Object, "spec", "selector")
+	return selector
+}
+
+func TestRunCutoverSwitchesDependents(t *testing.T) {
+	setCutoverTestGlobals(t)
+	client, dc := newCutoverClient(t, func() bool { return true })
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+	dependents, rewrites := addCutoverService(t, client, dc, deployment)
+
+	_, _, err = runCutover(context.Background(), client, dc, deployment, dependents, rewrites)
+	assert.NoError(t, err)
+	assert.True(t, rewrites[0].Applied)
+	// app=test-app is also a label of the DC pods, so the Service keeps
+	// selecting them until they are scaled down.
+	assert.Equal(t, map[string]string{"app": "test-app"}, liveServiceSelector(t, client))
+}
+
+func TestRunCutoverRestoresDependentsOnFailure(t *testing.T) {
+	setCutoverTestGlobals(t)
+	checks := 0
+	client, dc := newCutoverClient(t, func() bool {
+		checks++
+		return checks <= 1
+	})
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+	dependents, rewrites := addCutoverService(t, client, dc, deployment)
+
+	steps, _, err := runCutover(context.Background(), client, dc, deployment, dependents, rewrites)
+	assert.Error(t, err)
+	assert.Equal(t, "restore dependent resources", steps[len(steps)-2].Name)
+	assert.Empty(t, steps[len(steps)-2].Error)
+	assert.False(t, rewrites[0].Applied)
+	assert.Equal(t, map[string]string{"deploymentconfig": "test-dc"}, liveServiceSelector(t, client))
+}
+
+func TestCutoverScaleSteps(t *testing.T) {
+	assert.Equal(t, []int64{0}, cutoverScaleSteps(3, 0))
+	assert.Equal(t, []int64{0}, cutoverScaleSteps(3, 5))
+	assert.Equal(t, []int64{3, 1, 0}, cutoverScaleSteps(5, 2))
+}
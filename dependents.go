@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+// dcPodLabel is the label the DC controller adds to every pod it creates and
+// that selectors created by 'oc expose dc' match on.
+const dcPodLabel = "deploymentconfig"
+
+// DependentRewrite records the changes made to an object that referenced a
+// DeploymentConfig.
+type DependentRewrite struct {
+	Kind    string   `json:"kind"`
+	Name    string   `json:"name"`
+	Changes []string `json:"changes"`
+	Applied bool     `json:"applied"`
+}
+
+// dependentResource is a kind of object that can reference a DeploymentConfig,
+// either by name or through its pod labels. Rewrite updates the object in
+// place to reference the Deployment instead and describes what it changed.
+type dependentResource struct {
+	Kind    string
+	GVR     schema.GroupVersionResource
+	Rewrite func(obj *unstructured.Unstructured, dcName string, matchLabels map[string]interface{}) []string
+}
+
+// dependentsMu serializes the in-place rewrites of the dependent objects of a
+// namespace when its DeploymentConfigs are processed concurrently.
+var dependentsMu sync.Mutex
+
+// dependentObject is an object of a dependentResource listed from a namespace.
+type dependentObject struct {
+	Resource dependentResource
+	Object   *unstructured.Unstructured
+}
+
+var dependentResources = []dependentResource{
+	{
+		Kind:    "HorizontalPodAutoscaler",
+		GVR:     schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"},
+		Rewrite: rewriteTargetRef("spec", "scaleTargetRef"),
+	},
+	{
+		Kind:    "VerticalPodAutoscaler",
+		GVR:     schema.GroupVersionResource{Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"},
+		Rewrite: rewriteTargetRef("spec", "targetRef"),
+	},
+	{
+		Kind:    "Service",
+		GVR:     schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"},
+		Rewrite: rewriteMapSelector("spec", "selector"),
+	},
+	{
+		Kind:    "PodDisruptionBudget",
+		GVR:     schema.GroupVersionResource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"},
+		Rewrite: rewriteLabelSelector("spec", "selector"),
+	},
+	{
+		Kind:    "NetworkPolicy",
+		GVR:     schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+		Rewrite: rewriteNetworkPolicy,
+	},
+	{
+		Kind:    "ServiceMonitor",
+		GVR:     schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"},
+		Rewrite: rewriteLabelSelector("spec", "selector"),
+	},
+}
+
+// listDependents lists the objects of every dependent resource in the
+// namespace. Resources whose API is not installed are skipped, and so are
+// the ones the user may not list, which is logged since references to the
+// DeploymentConfigs among them are then left unchanged.
+func listDependents(client dynamic.Interface, namespace string) ([]dependentObject, error) {
+	ctx := context.Background()
+	var dependents []dependentObject
+	for _, resource := range dependentResources {
+		list, err := client.Resource(resource.GVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if apierrors.IsForbidden(err) {
+				logErr := logMessage(fmt.Sprintf("Warning: %s objects in project %s were not checked for references to DeploymentConfigs: %v", resource.Kind, namespace, err))
+				if logErr != nil {
+					fmt.Printf("Failed to log message: %v\n", logErr)
+				}
+				continue
+			}
+			return nil, fmt.Errorf("error listing %s in namespace %s: %w", resource.GVR.Resource, namespace, err)
+		}
+		for i := range list.Items {
+			dependents = append(dependents, dependentObject{Resource: resource, Object: &list.Items[i]})
+		}
+	}
+	return dependents, nil
+}
+
+// rewriteDependents rewrites the dependent objects that reference the
+// DeploymentConfig so they reference the converted Deployment. Objects are
+// updated in place so that rewrites for several DCs accumulate, and copies of
+// the rewritten objects are returned alongside a description of each rewrite.
+func rewriteDependents(dependents []dependentObject, dc, deployment *unstructured.Unstructured) ([]dependentObject, []DependentRewrite) {
+	matchLabels, _, _ := unstructured.NestedMap(deployment.Object, "spec", "selector", "matchLabels")
+
+	var rewritten []dependentObject
+	var rewrites []DependentRewrite
+	for _, dependent := range dependents {
+		changes := dependent.Resource.Rewrite(dependent.Object, dc.GetName(), matchLabels)
+		if len(changes) == 0 {
+			continue
+		}
+		rewritten = append(rewritten, dependentObject{Resource: dependent.Resource, Object: dependent.Object.DeepCopy()})
+		rewrites = append(rewrites, DependentRewrite{
+			Kind:    dependent.Resource.Kind,
+			Name:    dependent.Object.GetName(),
+			Changes: changes,
+		})
+	}
+	return rewritten, rewrites
+}
+
+// saveDependentYAML writes a rewritten dependent object, without its server
+// managed fields, to the dependents directory of the namespace.
+func saveDependentYAML(dependent dependentObject, namespace string) error {
+	manifest := dependent.Object.DeepCopy()
+	cleanServerFields(manifest)
+	name := fmt.Sprintf("%s-%s", strings.ToLower(dependent.Resource.Kind), manifest.GetName())
+	return writeManifestYAML(manifest, filepath.Join(outputDir, namespace, "dependents"), name)
+}
+
+// applyDependent rewrites the live copy of a dependent object for the
+// DeploymentConfig and updates it. Rewriting the live object, rather than
+// sending the listed one, keeps what other DCs sharing the object already
+// switched and sends its current resourceVersion; conflicts with other
+// writers are retried. It returns the object as it was before the update, or
+// nil if it was already switched.
+func applyDependent(client dynamic.Interface, dependent dependentObject, dc, deployment *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	ctx := context.Background()
+	namespace, name := dependent.Object.GetNamespace(), dependent.Object.GetName()
+	resource := client.Resource(dependent.Resource.GVR).Namespace(namespace)
+	matchLabels, _, _ := unstructured.NestedMap(deployment.Object, "spec", "selector", "matchLabels")
+	var previous *unstructured.Unstructured
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		previous = nil
+		live, err := resource.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		original := live.DeepCopy()
+		if len(dependent.Resource.Rewrite(live, dc.GetName(), matchLabels)) == 0 {
+			// Already switched, for example by an earlier run.
+			return nil
+		}
+		if _, err := resource.Update(ctx, live, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		previous = original
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error updating %s %s in namespace %s: %w", dependent.Resource.Kind, name, namespace, err)
+	}
+	return previous, nil
+}
+
+// restoreDependent puts back the spec a dependent object had before
+// applyDependent switched it to the Deployment.
+func restoreDependent(client dynamic.Interface, resource dependentResource, original *unstructured.Unstructured) error {
+	ctx := context.Background()
+	namespace, name := original.GetNamespace(), original.GetName()
+	objects := client.Resource(resource.GVR).Namespace(namespace)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		live, err := objects.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		live.Object["spec"] = runtime.DeepCopyJSONValue(original.Object["spec"])
+		_, err = objects.Update(ctx, live, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error restoring %s %s in namespace %s: %w", resource.Kind, name, namespace, err)
+	}
+	return nil
+}
+
+// rewriteTargetRef returns a rewrite that points a scale target reference at
+// the Deployment instead of the DeploymentConfig.
+func rewriteTargetRef(fields ...string) func(*unstructured.Unstructured, string, map[string]interface{}) []string {
+	return func(obj *unstructured.Unstructured, dcName string, _ map[string]interface{}) []string {
+		ref, found, _ := unstructured.NestedMap(obj.Object, fields...)
+		if !found || ref["kind"] != "DeploymentConfig" || ref["name"] != dcName {
+			return nil
+		}
+		ref["kind"] = "Deployment"
+		ref["apiVersion"] = "apps/v1"
+		if err := unstructured.SetNestedMap(obj.Object, ref, fields...); err != nil {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: DeploymentConfig %s -> Deployment %s", strings.Join(fields, "."), dcName, dcName)}
+	}
+}
+
+// rewriteMapSelector returns a rewrite for plain map selectors such as the one
+// of a Service.
+func rewriteMapSelector(fields ...string) func(*unstructured.Unstructured, string, map[string]interface{}) []string {
+	return func(obj *unstructured.Unstructured, dcName string, matchLabels map[string]interface{}) []string {
+		return rewriteSelectorAt(obj, dcName, matchLabels, fields)
+	}
+}
+
+// rewriteLabelSelector returns a rewrite for metav1.LabelSelector fields,
+// covering both matchLabels and matchExpressions.
+func rewriteLabelSelector(fields ...string) func(*unstructured.Unstructured, string, map[string]interface{}) []string {
+	return func(obj *unstructured.Unstructured, dcName string, matchLabels map[string]interface{}) []string {
+		return rewriteLabelSelectorAt(obj.Object, dcName, matchLabels, fields)
+	}
+}
+
+func rewriteLabelSelectorAt(obj map[string]interface{}, dcName string, matchLabels map[string]interface{}, fields []string) []string {
+	path := strings.Join(fields, ".")
+	selector, found, _ := unstructured.NestedMap(obj, fields...)
+	if !found {
+		return nil
+	}
+
+	referenced := false
+	if labels, ok := selector["matchLabels"].(map[string]interface{}); ok && labels[dcPodLabel] == dcName {
+		delete(labels, dcPodLabel)
+		referenced = true
+	}
+	if expressions, ok := selector["matchExpressions"].([]interface{}); ok {
+		var kept []interface{}
+		for _, e := range expressions {
+			if expression, ok := e.(map[string]interface{}); ok && isDCExpression(expression, dcName) {
+				referenced = true
+				continue
+			}
+			kept = append(kept, e)
+		}
+		if len(kept) > 0 {
+			selector["matchExpressions"] = kept
+		} else {
+			delete(selector, "matchExpressions")
+		}
+	}
+	if !referenced {
+		return nil
+	}
+
+	labels, _ := selector["matchLabels"].(map[string]interface{})
+	if labels == nil {
+		labels = map[string]interface{}{}
+	}
+	added := mergeLabels(labels, matchLabels)
+	selector["matchLabels"] = labels
+	if err := unstructured.SetNestedMap(obj, selector, fields...); err != nil {
+		return nil
+	}
+	return []string{selectorChange(path+".matchLabels", dcName, added)}
+}
+
+func rewriteSelectorAt(obj *unstructured.Unstructured, dcName string, matchLabels map[string]interface{}, fields []string) []string {
+	selector, found, _ := unstructured.NestedMap(obj.Object, fields...)
+	if !found || selector[dcPodLabel] != dcName {
+		return nil
+	}
+	delete(selector, dcPodLabel)
+	added := mergeLabels(selector, matchLabels)
+	if err := unstructured.SetNestedMap(obj.Object, selector, fields...); err != nil {
+		return nil
+	}
+	return []string{selectorChange(strings.Join(fields, "."), dcName, added)}
+}
+
+// rewriteNetworkPolicy rewrites the pod selector of the policy and of every
+// ingress and egress peer.
+func rewriteNetworkPolicy(obj *unstructured.Unstructured, dcName string, matchLabels map[string]interface{}) []string {
+	changes := rewriteLabelSelectorAt(obj.Object, dcName, matchLabels, []string{"spec", "podSelector"})
+	for _, direction := range []struct{ rules, peers string }{{"ingress", "from"}, {"egress", "to"}} {
+		rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", direction.rules)
+		for i, r := range rules {
+			rule, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			peers, _ := rule[direction.peers].([]interface{})
+			for j, p := range peers {
+				peer, ok := p.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				for _, change := range rewriteLabelSelectorAt(peer, dcName, matchLabels, []string{"podSelector"}) {
+					changes = append(changes, fmt.Sprintf("spec.%s[%d].%s[%d].%s", direction.rules, i, direction.peers, j, change))
+				}
+			}
+		}
+		if len(rules) > 0 {
+			if err := unstructured.SetNestedSlice(obj.Object, rules, "spec", direction.rules); err != nil {
+				return nil
+			}
+		}
+	}
+	return changes
+}
+
+func isDCExpression(expression map[string]interface{}, dcName string) bool {
+	if expression["key"] != dcPodLabel || expression["operator"] != "In" {
+		return false
+	}
+	values, _ := expression["values"].([]interface{})
+	return len(values) == 1 && values[0] == dcName
+}
+
+// mergeLabels adds the labels missing from selector and returns them as
+// sorted key=value pairs.
+func mergeLabels(selector, labels map[string]interface{}) []string {
+	var added []string
+	for k, v := range labels {
+		if _, exists := selector[k]; !exists {
+			selector[k] = v
+			added = append(added, fmt.Sprintf("%s=%v", k, v))
+		}
+	}
+	sort.Strings(added)
+	return added
+}
+
+func selectorChange(path, dcName string, added []string) string {
+	if len(added) == 0 {
+		return fmt.Sprintf("%s: removed %s=%s", path, dcPodLabel, dcName)
+	}
+	return fmt.Sprintf("%s: replaced %s=%s with %s", path, dcPodLabel, dcName, strings.Join(added, ","))
+}
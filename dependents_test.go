@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newDependent(apiVersion, kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":            name,
+				"namespace":       "test-namespace",
+				"resourceVersion": "7",
+			},
+			"spec": spec,
+		},
+	}
+}
+
+func newDependentsClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, resource := range dependentResources {
+		listKinds[resource.GVR] = resource.Kind + "List"
+	}
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+}
+
+func TestRewriteDependents(t *testing.T) {
+	client := newDependentsClient(
+		newDependent("autoscaling/v2", "HorizontalPodAutoscaler", "web", map[string]interface{}{
+			"scaleTargetRef": map[string]interface{}{"apiVersion": "apps.openshift.io/v1", "kind": "DeploymentConfig", "name": "test-dc"},
+		}),
+		newDependent("autoscaling/v2", "HorizontalPodAutoscaler", "other", map[string]interface{}{
+			"scaleTargetRef": map[string]interface{}{"apiVersion": "apps.openshift.io/v1", "kind": "DeploymentConfig", "name": "other-dc"},
+		}),
+		newDependent("v1", "Service", "web", map[string]interface{}{
+			"selector": map[string]interface{}{"deploymentconfig": "test-dc"},
+		}),
+		newDependent("policy/v1", "PodDisruptionBudget", "web", map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchExpressions": []interface{}{
+					map[string]interface{}{"key": "deploymentconfig", "operator": "In", "values": []interface{}{"test-dc"}},
+				},
+			},
+		}),
+		newDependent("networking.k8s.io/v1", "NetworkPolicy", "web", map[string]interface{}{
+			"podSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "test-app"}},
+			"ingress": []interface{}{
+				map[string]interface{}{
+					"from": []interface{}{
+						map[string]interface{}{"podSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"deploymentconfig": "test-dc"}}},
+					},
+				},
+			},
+		}),
+	)
+
+	dependents, err := listDependents(client, "test-namespace")
+	assert.NoError(t, err)
+	assert.Len(t, dependents, 5)
+
+	dc := newHookedDC()
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	rewritten, rewrites := rewriteDependents(dependents, dc, deployment)
+	assert.Len(t, rewritten, 4)
+	assert.Equal(t, []DependentRewrite{
+		{Kind: "HorizontalPodAutoscaler", Name: "web", Changes: []string{"spec.scaleTargetRef: DeploymentConfig test-dc -> Deployment test-dc"}},
+		{Kind: "Service", Name: "web", Changes: []string{"spec.selector: replaced deploymentconfig=test-dc with app=test-app"}},
+		{Kind: "PodDisruptionBudget", Name: "web", Changes: []string{"spec.selector.matchLabels: replaced deploymentconfig=test-dc with app=test-app"}},
+		{Kind: "NetworkPolicy", Name: "web", Changes: []string{"spec.ingress[0].from[0].podSelector.matchLabels: replaced deploymentconfig=test-dc with app=test-app"}},
+	}, rewrites)
+
+	hpa := rewritten[0].Object
+	kind, _, _ := unstructured.NestedString(hpa.Object, "spec", "scaleTargetRef", "kind")
+	assert.Equal(t, "Deployment", kind)
+
+	pdb := rewritten[2].Object
+	_, found, _ := unstructured.NestedSlice(pdb.Object, "spec", "selector", "matchExpressions")
+	assert.False(t, found)
+
+	networkPolicy := rewritten[3].Object
+	ingress, _, _ := unstructured.NestedSlice(networkPolicy.Object, "spec", "ingress")
+	peer := ingress[0].(map[string]interface{})["from"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"app": "test-app"}, peer["podSelector"].(map[string]interface{})["matchLabels"])
+
+	// Rewrites are applied in place, so a second pass finds nothing left to do.
+	_, rewrites = rewriteDependents(dependents, dc, deployment)
+	assert.Empty(t, rewrites)
+
+	_, err = applyDependent(client, rewritten[0], dc, deployment)
+	assert.NoError(t, err)
+}
+
+func TestListDependentsSkipsForbiddenResources(t *testing.T) {
+	logFilePath = filepath.Join(t.TempDir(), "log.txt")
+	client := newDependentsClient(newDependent("v1", "Service", "web", map[string]interface{}{
+		"selector": map[string]interface{}{"deploymentconfig": "test-dc"},
+	}))
+	for _, resource := range []string{"verticalpodautoscalers", "servicemonitors"} {
+		resource := resource
+		client.PrependReactor("list", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: resource}, "", assert.AnError)
+		})
+	}
+
+	dependents, err := listDependents(client, "test-namespace")
+	assert.NoError(t, err)
+	assert.Len(t, dependents, 1)
+	assert.Equal(t, "Service", dependents[0].Resource.Kind)
+
+	log, err := os.ReadFile(logFilePath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(log), "VerticalPodAutoscaler objects in project test-namespace were not checked for references to DeploymentConfigs")
+	assert.Contains(t, string(log), "ServiceMonitor objects in project test-namespace were not checked")
+}
+
+func TestApplyDependentSharedByTwoDCs(t *testing.T) {
+	peer := func(dcName string) interface{} {
+		return map[string]interface{}{"podSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"deploymentconfig": dcName}}}
+	}
+	client := newDependentsClient(newDependent("networking.k8s.io/v1", "NetworkPolicy", "shared", map[string]interface{}{
+		"podSelector": map[string]interface{}{},
+		"ingress": []interface{}{
+			map[string]interface{}{"from": []interface{}{peer("dc-a"), peer("dc-b")}},
+		},
+	}))
+	// Reject updates based on a stale resourceVersion like the API server.
+	networkPolicyRes := dependentResources[4].GVR
+	client.PrependReactor("update", "networkpolicies", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		live, err := client.Tracker().Get(networkPolicyRes, obj.GetNamespace(), obj.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		liveVersion := live.(*unstructured.Unstructured).GetResourceVersion()
+		if obj.GetResourceVersion() != liveVersion {
+			return true, nil, apierrors.NewConflict(networkPolicyRes.GroupResource(), obj.GetName(), fmt.Errorf("stale resourceVersion %s", obj.GetResourceVersion()))
+		}
+		version, _ := strconv.Atoi(liveVersion)
+		obj.SetResourceVersion(strconv.Itoa(version + 1))
+		return false, nil, nil
+	})
+
+	dependents, err := listDependents(client, "test-namespace")
+	assert.NoError(t, err)
+	for _, name := range []string{"dc-a", "dc-b"} {
+		dc := newHookedDC()
+		dc.SetName(name)
+		deployment, err := convertDCtoDeployment(dc, nil)
+		assert.NoError(t, err)
+		rewritten, _ := rewriteDependents(dependents, dc, deployment)
+		assert.Len(t, rewritten, 1)
+		previous, err := applyDependent(client, rewritten[0], dc, deployment)
+		assert.NoError(t, err)
+		assert.NotNil(t, previous)
+	}
+
+	live, err := client.Resource(networkPolicyRes).Namespace("test-namespace").Get(context.Background(), "shared", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "9", live.GetResourceVersion())
+	ingress, _, _ := unstructured.NestedSlice(live.Object, "spec", "ingress")
+	for _, p := range ingress[0].(map[string]interface{})["from"].([]interface{}) {
+		assert.Equal(t, map[string]interface{}{"app": "test-app"}, p.(map[string]interface{})["podSelector"].(map[string]interface{})["matchLabels"])
+	}
+}
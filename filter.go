@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// skipAnnotation opts a DeploymentConfig out of the migration when set to
+// "true".
+const skipAnnotation = "migration.openshift.io/skip"
+
+// dcLabelSelector is the parsed --selector, nil when every DeploymentConfig
+// is selected.
+var dcLabelSelector labels.Selector
+
+// parseDCFilters checks the --selector, --include and --exclude flags.
+func parseDCFilters() error {
+	dcLabelSelector = nil
+	if dcSelector != "" {
+		selector, err := labels.Parse(dcSelector)
+		if err != nil {
+			return fmt.Errorf("invalid --selector %q: %w", dcSelector, err)
+		}
+		dcLabelSelector = selector
+	}
+	for _, pattern := range append(append([]string{}, includePatterns...), excludePatterns...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid DeploymentConfig name pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// skipReason returns why the DeploymentConfig is left out of the migration by
+// the skip annotation, --selector, --include or --exclude, or an empty string
+// when it is to be converted.
+func skipReason(dc *unstructured.Unstructured) string {
+	if skip, err := strconv.ParseBool(dc.GetAnnotations()[skipAnnotation]); err == nil && skip {
+		return fmt.Sprintf("annotated with %s=true", skipAnnotation)
+	}
+	if dcLabelSelector != nil && !dcLabelSelector.Matches(labels.Set(dc.GetLabels())) {
+		return fmt.Sprintf("labels do not match --selector %s", dcLabelSelector)
+	}
+	if len(includePatterns) > 0 && !matchesAnyPattern(dc.GetName(), includePatterns) {
+		return fmt.Sprintf("name does not match --include %s", strings.Join(includePatterns, ","))
+	}
+	for _, pattern := range excludePatterns {
+		if matched, _ := path.Match(pattern, dc.GetName()); matched {
+			return fmt.Sprintf("name matches --exclude %s", pattern)
+		}
+	}
+	return ""
+}
+
+// filterDCs returns the DeploymentConfigs to convert and records the others
+// as skipped so that they still appear in the report.
+func filterDCs(dcs []unstructured.Unstructured, namespace string) []unstructured.Unstructured {
+	var selected []unstructured.Unstructured
+	for i := range dcs {
+		if reason := skipReason(&dcs[i]); reason != "" {
+			recordSkippedDC(&dcs[i], namespace, reason)
+			continue
+		}
+		selected = append(selected, dcs[i])
+	}
+	return selected
+}
+
+func recordSkippedDC(dc *unstructured.Unstructured, namespace, reason string) {
+	recordConversionInfos(skippedConversionInfo(dc, namespace, reason))
+}
+
+// skippedConversionInfo logs why a DeploymentConfig is skipped and returns the
+// ConversionInfo listing it in the report.
+func skippedConversionInfo(dc *unstructured.Unstructured, namespace, reason string) ConversionInfo {
+	logErr := logMessage(fmt.Sprintf("Skipping DeploymentConfig %s in project %s: %s", dc.GetName(), namespace, reason))
+	if logErr != nil {
+		fmt.Printf("Failed to log message: %v\n", logErr)
+	}
+	return ConversionInfo{
+		Timestamp:            time.Now().Format(time.RFC3339),
+		Namespace:            namespace,
+		DeploymentConfigName: dc.GetName(),
+		Outcome:              OutcomeSkipped,
+		SkipReason:           reason,
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newFilterDC(name string, labels, annotations map[string]string) unstructured.Unstructured {
+	dc := newTestDC(withField(name, "metadata", "name"))
+	dc.SetLabels(labels)
+	dc.SetAnnotations(annotations)
+	return *dc
+}
+
+func TestSkipReason(t *testing.T) {
+	defer func() {
+		dcSelector, includePatterns, excludePatterns = "", nil, nil
+		dcLabelSelector = nil
+	}()
+	dcSelector = "tier=frontend"
+	includePatterns = []string{"web-*", "api"}
+	excludePatterns = []string{"*-canary"}
+	assert.NoError(t, parseDCFilters())
+
+	tests := []struct {
+		name     string
+		dc       unstructured.Unstructured
+		expected string
+	}{
+		{"Selected", newFilterDC("web-1", map[string]string{"tier": "frontend"}, nil), ""},
+		{"Skip annotation", newFilterDC("web-1", map[string]string{"tier": "frontend"}, map[string]string{skipAnnotation: "true"}), "annotated with migration.openshift.io/skip=true"},
+		{"Skip annotation false", newFilterDC("api", map[string]string{"tier": "frontend"}, map[string]string{skipAnnotation: "false"}), ""},
+		{"Selector mismatch", newFilterDC("web-1", map[string]string{"tier": "backend"}, nil), "labels do not match --selector tier=frontend"},
+		{"Not included", newFilterDC("worker", map[string]string{"tier": "frontend"}, nil), "name does not match --include web-*,api"},
+		{"Excluded", newFilterDC("web-canary", map[string]string{"tier": "frontend"}, nil), "name matches --exclude *-canary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, skipReason(&tt.dc))
+		})
+	}
+}
+
+func TestParseDCFiltersErrors(t *testing.T) {
+	defer func() { dcSelector, excludePatterns = "", nil }()
+
+	dcSelector = "tier in ("
+	assert.ErrorContains(t, parseDCFilters(), "invalid --selector")
+
+	dcSelector = ""
+	excludePatterns = []string{"web-["}
+	assert.ErrorContains(t, parseDCFilters(), "invalid DeploymentConfig name pattern")
+}
+
+func TestFilterDCsRecordsSkipped(t *testing.T) {
+	logFilePath = filepath.Join(t.TempDir(), "log.txt")
+	conversionInfos = nil
+	defer func() { excludePatterns = nil }()
+	excludePatterns = []string{"legacy-*"}
+
+	dcs := []unstructured.Unstructured{
+		newFilterDC("web", nil, nil),
+		newFilterDC("legacy-web", nil, nil),
+		newFilterDC("worker", nil, map[string]string{skipAnnotation: "true"}),
+	}
+	selected := filterDCs(dcs, "test-namespace")
+
+	assert.Len(t, selected, 1)
+	assert.Equal(t, "web", selected[0].GetName())
+	assert.Len(t, conversionInfos, 2)
+	for _, info := range conversionInfos {
+		assert.NotEmpty(t, info.SkipReason)
+		assert.Equal(t, "test-namespace", info.Namespace)
+	}
+}
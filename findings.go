@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Severity grades a finding.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// severityRank orders the severities from least to most severe.
+var severityRank = map[Severity]int{
+	SeverityInfo:    0,
+	SeverityWarning: 1,
+	SeverityError:   2,
+}
+
+// Finding is something a detector or converter stage noticed about a
+// DeploymentConfig, with what to do about it.
+type Finding struct {
+	Code        string   `json:"code"`
+	Severity    Severity `json:"severity"`
+	Field       string   `json:"field"`
+	Message     string   `json:"message"`
+	Remediation string   `json:"remediation"`
+}
+
+// Finding codes.
+const (
+	codeTriggers            = "triggers"
+	codeManualImageTrigger  = "manual-image-trigger"
+	codeAutoRollback        = "auto-rollback"
+	codeCustomStrategy      = "custom-strategy"
+	codeUnmappedField       = "unmapped-field"
+	codeRolloutTiming       = "rollout-timing"
+	codeSelectorRepair      = "selector-repair"
+	codeSelectorOverlap     = "selector-overlap"
+	codeSynthesizedSelector = "synthesized-selector"
+	codeHook                = "hook"
+	codeUnresolvedImage     = "unresolved-image"
+	codeUnpinnedImage       = "unpinned-image"
+	codeInvalidDeployment   = "invalid-deployment"
+	codeValidationSkipped   = "validation-skipped"
+	codeAdmissionWarning    = "admission-warning"
+	codeAdmissionError      = "admission-error"
+	codeApplyConflict       = "apply-conflict"
+)
+
+// findingDefinition holds the severity and remediation shared by the
+// findings of a code.
+type findingDefinition struct {
+	Severity    Severity
+	Remediation string
+}
+
+var findingDefinitions = map[string]findingDefinition{
+	codeTriggers: {
+		Severity:    SeverityInfo,
+		Remediation: "ImageChange triggers are carried over as the image.openshift.io/triggers annotation and ConfigChange triggers are the default behaviour of Deployments; check that image updates still roll out.",
+	},
+	codeManualImageTrigger: {
+		Severity:    SeverityWarning,
+		Remediation: "Roll out new images from the CI/CD pipeline, or make the trigger automatic and point it at an ImageStreamTag before converting again.",
+	},
+	codeAutoRollback: {
+		Severity:    SeverityWarning,
+		Remediation: "Deployments do not roll back on their own; watch progressDeadlineSeconds and run 'oc rollout undo', or use a progressive delivery tool.",
+	},
+	codeCustomStrategy: {
+		Severity:    SeverityError,
+		Remediation: "Deployments have no custom strategy; reimplement the custom deployer as a Job or pipeline step and review the converted strategy.",
+	},
+	codeUnmappedField: {
+		Severity:    SeverityWarning,
+		Remediation: "The field has no Deployment equivalent and was dropped; reproduce its behaviour outside the Deployment or confirm it is not needed.",
+	},
+	codeRolloutTiming: {
+		Severity:    SeverityInfo,
+		Remediation: "Check that progressDeadlineSeconds leaves enough time for the slowest rollout.",
+	},
+	codeSelectorRepair: {
+		Severity:    SeverityWarning,
+		Remediation: "Deployment selectors are immutable; confirm the repaired selector before applying, as changing it later means recreating the Deployment.",
+	},
+	codeSelectorOverlap: {
+		Severity:    SeverityWarning,
+		Remediation: "Give the Deployments distinct pod labels so that they do not fight over the same pods.",
+	},
+	codeSynthesizedSelector: {
+		Severity:    SeverityWarning,
+		Remediation: "Services switched to the Deployment stop selecting the DeploymentConfig pods at once; add a label such as app to the DeploymentConfig pod template and roll it out before converting, or switch over in a maintenance window.",
+	},
+	codeHook: {
+		Severity:    SeverityInfo,
+		Remediation: "Hook Jobs are only written, never applied; run them through Argo CD or Helm hooks, or from the pipeline.",
+	},
+	codeUnresolvedImage: {
+		Severity:    SeverityWarning,
+		Remediation: "Set the container image to a pullable reference, preferably pinned by digest, before applying the Deployment.",
+	},
+	codeUnpinnedImage: {
+		Severity:    SeverityWarning,
+		Remediation: "The tag may point at a different image than the running pods by the time the Deployment rolls out; pin the container image by digest before applying the Deployment.",
+	},
+	codeInvalidDeployment: {
+		Severity:    SeverityError,
+		Remediation: "Fix the DeploymentConfig and convert it again, or rerun with --allow-invalid to save and apply the Deployment anyway.",
+	},
+	codeValidationSkipped: {
+		Severity:    SeverityInfo,
+		Remediation: "Process the Template with 'oc process' and validate the resulting Deployment, for example with 'oc apply --dry-run=server'.",
+	},
+	codeAdmissionWarning: {
+		Severity:    SeverityWarning,
+		Remediation: "Adjust the pod template so that the cluster policies no longer warn about it.",
+	},
+	codeAdmissionError: {
+		Severity:    SeverityError,
+		Remediation: "The cluster would reject the Deployment; adjust it to the admission policies of the project before applying.",
+	},
+	codeApplyConflict: {
+		Severity:    SeverityError,
+		Remediation: "Another field manager owns fields of the existing Deployment; rerun with --force-conflicts to take them over.",
+	},
+}
+
+// newFinding returns a finding of the code about the field, given by its path
+// in the DeploymentConfig or the Deployment, with the severity and
+// remediation of its definition.
+func newFinding(code, field, message string) Finding {
+	definition := findingDefinitions[code]
+	return Finding{
+		Code:        code,
+		Severity:    definition.Severity,
+		Field:       field,
+		Message:     message,
+		Remediation: definition.Remediation,
+	}
+}
+
+// withSeverity returns the finding with another severity, for the findings
+// that are more serious than the usual ones of their code.
+func (f Finding) withSeverity(severity Severity) Finding {
+	f.Severity = severity
+	return f
+}
+
+// findingsOf returns the findings of the ConversionInfo with one of the
+// codes.
+func (info ConversionInfo) findingsOf(codes ...string) []Finding {
+	var findings []Finding
+	for _, finding := range info.Findings {
+		if contains(codes, finding.Code) {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+func (info ConversionInfo) hasFinding(code string) bool {
+	return len(info.findingsOf(code)) > 0
+}
+
+// countFindings counts the findings of the ConversionInfos at or above the
+// severity.
+func countFindings(infos []ConversionInfo, severity Severity) int {
+	count := 0
+	for _, info := range infos {
+		for _, finding := range info.Findings {
+			if severityRank[finding.Severity] >= severityRank[severity] {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// formatFinding renders a finding on a single line for logs and reports.
+func formatFinding(finding Finding) string {
+	return fmt.Sprintf("[%s] %s: %s", finding.Severity, finding.Field, finding.Message)
+}
+
+// logFindings writes every finding of the ConversionInfo to the log file.
+func logFindings(info ConversionInfo) {
+	for _, finding := range info.Findings {
+		logErr := logMessage(fmt.Sprintf("DeploymentConfig %s in project %s: %s %s", displayName(info), info.Namespace, finding.Code, formatFinding(finding)))
+		if logErr != nil {
+			fmt.Printf("Failed to log message: %v\n", logErr)
+		}
+	}
+}
+
+// FindingsError is returned when findings reach the --fail-on severity.
+// DeploymentConfigs that failed or panicked count as error findings.
+type FindingsError struct {
+	Count    int
+	Failed   int
+	Severity Severity
+}
+
+func (e *FindingsError) Error() string {
+	if e.Failed > 0 {
+		return fmt.Sprintf("%d findings at or above %s severity and %d failed DeploymentConfigs", e.Count, e.Severity, e.Failed)
+	}
+	return fmt.Sprintf("%d findings at or above %s severity", e.Count, e.Severity)
+}
+
+// checkFailOn returns a FindingsError when a finding reaches the --fail-on
+// severity, or a DeploymentConfig failed or panicked.
+func checkFailOn(cmd *cobra.Command, infos []ConversionInfo) error {
+	if failOn == "" || failOn == "none" {
+		return nil
+	}
+	failed := 0
+	for _, info := range infos {
+		if info.Outcome.failed() {
+			failed++
+		}
+	}
+	if count := countFindings(infos, Severity(failOn)); count > 0 || failed > 0 {
+		// Reaching --fail-on is not a usage error.
+		cmd.SilenceUsage = true
+		return &FindingsError{Count: count, Failed: failed, Severity: Severity(failOn)}
+	}
+	return nil
+}
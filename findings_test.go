@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewFinding(t *testing.T) {
+	finding := newFinding(codeRolloutTiming, "spec.progressDeadlineSeconds", "progressDeadlineSeconds set to 600 from strategy.rollingParams.timeoutSeconds")
+	assert.Equal(t, SeverityInfo, finding.Severity)
+	assert.Equal(t, "spec.progressDeadlineSeconds", finding.Field)
+	assert.NotEmpty(t, finding.Remediation)
+
+	// Messages are kept as they are; only withSeverity changes the severity.
+	finding = newFinding(codeHook, "spec.strategy.rollingParams.pre", "Warning: hook").withSeverity(SeverityWarning)
+	assert.Equal(t, SeverityWarning, finding.Severity)
+	assert.Equal(t, "Warning: hook", finding.Message)
+}
+
+func TestDetectFindings(t *testing.T) {
+	dc := newScanDC(map[string]interface{}{
+		"type":          "Rolling",
+		"rollingParams": map[string]interface{}{"autoRollbackEnabled": true},
+	})
+	assert.NoError(t, unstructured.SetNestedField(dc.Object, true, "spec", "test"))
+
+	info := newConversionInfo(dc, "test-namespace", nil)
+	assert.True(t, info.hasFinding(codeTriggers))
+	assert.Equal(t, "spec.strategy.rollingParams.autoRollbackEnabled", info.findingsOf(codeAutoRollback)[0].Field)
+	assert.False(t, info.hasFinding(codeCustomStrategy))
+
+	unmapped := info.findingsOf(codeUnmappedField)
+	assert.Len(t, unmapped, 1)
+	assert.Equal(t, "spec.test", unmapped[0].Field)
+	assert.Equal(t, SeverityWarning, unmapped[0].Severity)
+}
+
+func TestCheckFailOn(t *testing.T) {
+	defer func() { failOn = "" }()
+	infos := []ConversionInfo{{
+		Findings: []Finding{
+			newFinding(codeTriggers, "spec.triggers", "triggers"),
+			newFinding(codeUnmappedField, "spec.test", "spec.test"),
+		},
+	}}
+
+	for _, tt := range []struct {
+		failOn   string
+		expected int
+	}{
+		{"none", 0},
+		{"error", 0},
+		{"warning", 1},
+		{"info", 2},
+	} {
+		failOn = tt.failOn
+		cmd := &cobra.Command{}
+		err := checkFailOn(cmd, infos)
+		if tt.expected == 0 {
+			assert.NoError(t, err, tt.failOn)
+			continue
+		}
+		var findingsErr *FindingsError
+		assert.ErrorAs(t, err, &findingsErr)
+		assert.Equal(t, tt.expected, findingsErr.Count)
+		assert.True(t, cmd.SilenceUsage)
+	}
+}
+
+func TestCheckFailOnFailedOutcomes(t *testing.T) {
+	defer func() { failOn = "" }()
+	failOn = "error"
+	infos := []ConversionInfo{
+		{Outcome: OutcomeConverted},
+		{Outcome: OutcomeFailed, Error: "error saving Deployment YAML"},
+		{Outcome: OutcomePanicked, Error: "runtime error"},
+	}
+
+	err := checkFailOn(&cobra.Command{}, infos)
+	var findingsErr *FindingsError
+	assert.ErrorAs(t, err, &findingsErr)
+	assert.Equal(t, 0, findingsErr.Count)
+	assert.Equal(t, 2, findingsErr.Failed)
+	assert.EqualError(t, err, "0 findings at or above error severity and 2 failed DeploymentConfigs")
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// lifecycleHook is a pre, mid or post hook declared in the strategy
+// parameters of a DeploymentConfig.
+type lifecycleHook struct {
+	Params        string
+	Phase         string
+	FailurePolicy string
+	ExecNewPod    map[string]interface{}
+	TagImages     []interface{}
+}
+
+// HookSummary describes the actions of a lifecycle hook found in a
+// DeploymentConfig strategy.
+type HookSummary struct {
+	Params        string   `json:"params"`
+	Phase         string   `json:"phase"`
+	Actions       []string `json:"actions"`
+	FailurePolicy string   `json:"failurePolicy,omitempty"`
+}
+
+// hookParamsByStrategy maps strategy types to the parameter block whose hooks
+// the DC controller actually runs.
+var hookParamsByStrategy = map[string]string{
+	"Recreate": "recreateParams",
+	"Rolling":  "rollingParams",
+}
+
+// hookPhaseAnnotations maps each DeploymentConfig hook phase to Argo CD and
+// Helm hook annotations. Mid hooks have no equivalent and run as the last
+// pre-sync hook instead.
+var hookPhaseAnnotations = map[string]map[string]string{
+	"pre": {
+		"argocd.argoproj.io/hook":      "PreSync",
+		"argocd.argoproj.io/sync-wave": "0",
+		"helm.sh/hook":                 "pre-install,pre-upgrade",
+		"helm.sh/hook-weight":          "0",
+	},
+	"mid": {
+		"argocd.argoproj.io/hook":      "PreSync",
+		"argocd.argoproj.io/sync-wave": "1",
+		"helm.sh/hook":                 "pre-install,pre-upgrade",
+		"helm.sh/hook-weight":          "1",
+	},
+	"post": {
+		"argocd.argoproj.io/hook":      "PostSync",
+		"argocd.argoproj.io/sync-wave": "0",
+		"helm.sh/hook":                 "post-install,post-upgrade",
+		"helm.sh/hook-weight":          "0",
+	},
+}
+
+// hookFailurePolicyBackoffLimits maps DeploymentConfig hook failure policies
+// to Job backoffLimits.
+var hookFailurePolicyBackoffLimits = map[string]int64{
+	"Abort":  0,
+	"Retry":  6,
+	"Ignore": 0,
+}
+
+func getLifecycleHooks(dc *unstructured.Unstructured) []lifecycleHook {
+	var hooks []lifecycleHook
+	for _, params := range []string{"recreateParams", "rollingParams"} {
+		for _, phase := range []string{"pre", "mid", "post"} {
+			hook, found, _ := unstructured.NestedMap(dc.Object, "spec", "strategy", params, phase)
+			if !found {
+				continue
+			}
+			failurePolicy, _, _ := unstructured.NestedString(hook, "failurePolicy")
+			execNewPod, _, _ := unstructured.NestedMap(hook, "execNewPod")
+			tagImages, _, _ := unstructured.NestedSlice(hook, "tagImages")
+			hooks = append(hooks, lifecycleHook{
+				Params:        params,
+				Phase:         phase,
+				FailurePolicy: failurePolicy,
+				ExecNewPod:    execNewPod,
+				TagImages:     tagImages,
+			})
+		}
+	}
+	return hooks
+}
+
+// hookField returns the path of a hook in the DeploymentConfig.
+func hookField(hook lifecycleHook) string {
+	return fmt.Sprintf("spec.strategy.%s.%s", hook.Params, hook.Phase)
+}
+
+// analyzeLifecycleHooks summarizes the hooks declared under both recreateParams
+// and rollingParams and returns warnings for hooks that behave differently
+// than they appear to.
+func analyzeLifecycleHooks(dc *unstructured.Unstructured) ([]HookSummary, []Finding) {
+	strategyType, _, _ := unstructured.NestedString(dc.Object, "spec", "strategy", "type")
+	if strategyType == "" {
+		strategyType = "Rolling"
+	}
+
+	var summaries []HookSummary
+	var warnings []Finding
+	for _, hook := range getLifecycleHooks(dc) {
+		summary := HookSummary{
+			Params:        hook.Params,
+			Phase:         hook.Phase,
+			FailurePolicy: hook.FailurePolicy,
+		}
+		if hook.ExecNewPod != nil {
+			summary.Actions = append(summary.Actions, "execNewPod")
+		}
+		if len(hook.TagImages) > 0 {
+			summary.Actions = append(summary.Actions, "tagImages")
+		}
+		summaries = append(summaries, summary)
+
+		var messages []string
+		if len(summary.Actions) == 0 {
+			messages = append(messages, fmt.Sprintf("%s %s hook has no execNewPod or tagImages action", hook.Params, hook.Phase))
+		}
+		if expected, ok := hookParamsByStrategy[strategyType]; ok && expected != hook.Params {
+			messages = append(messages, fmt.Sprintf("%s %s hook is not run by the %s strategy and is not converted", hook.Params, hook.Phase, strategyType))
+		}
+		if hook.Params == "rollingParams" && hook.Phase == "mid" {
+			messages = append(messages, "rollingParams do not support mid hooks")
+		}
+		for _, message := range messages {
+			warnings = append(warnings, newFinding(codeHook, hookField(hook), message).withSeverity(SeverityWarning))
+		}
+	}
+	return summaries, warnings
+}
+
+// hookActions returns the actions of the hooks found for a phase, or "-" when
+// the DeploymentConfig has no hook for it.
+func hookActions(hooks []HookSummary, phase string) string {
+	var actions []string
+	for _, hook := range hooks {
+		if hook.Phase != phase {
+			continue
+		}
+		for _, action := range hook.Actions {
+			if !contains(actions, action) {
+				actions = append(actions, action)
+			}
+		}
+		if len(hook.Actions) == 0 && !contains(actions, "empty") {
+			actions = append(actions, "empty")
+		}
+	}
+	if len(actions) == 0 {
+		return "-"
+	}
+	return strings.Join(actions, ", ")
+}
+
+// convertHooksToJobs builds a Job manifest for every execNewPod hook of the
+// DeploymentConfig, using the converted Deployment's pod template as the base
+// for the hook pod. Hooks in the parameter block the strategy does not use
+// never ran and are skipped. It also returns notes explaining how each hook
+// was mapped.
+func convertHooksToJobs(dc, deployment *unstructured.Unstructured) ([]*unstructured.Unstructured, []Finding, error) {
+	var jobs []*unstructured.Unstructured
+	var notes []Finding
+
+	strategyType, _, _ := unstructured.NestedString(dc.Object, "spec", "strategy", "type")
+	if strategyType == "" {
+		strategyType = "Rolling"
+	}
+	expected, known := hookParamsByStrategy[strategyType]
+	var hooks []lifecycleHook
+	phases := map[string]int{}
+	for _, hook := range getLifecycleHooks(dc) {
+		if known && hook.Params != expected {
+			continue
+		}
+		hooks = append(hooks, hook)
+		if hook.ExecNewPod != nil {
+			phases[hook.Phase]++
+		}
+	}
+
+	for _, hook := range hooks {
+		if len(hook.TagImages) > 0 {
+			notes = append(notes, newFinding(codeHook, hookField(hook)+".tagImages",
+				fmt.Sprintf("%s %s hook uses tagImages, which has no Job equivalent; tag the images with 'oc tag' in the pipeline instead", hook.Params, hook.Phase)).withSeverity(SeverityWarning))
+		}
+		if hook.ExecNewPod == nil {
+			continue
+		}
+
+		// Strategies without a parameter block of their own, such as Custom,
+		// may declare a phase in both blocks.
+		name := hookJobName(dc.GetName(), hook.Phase)
+		if phases[hook.Phase] > 1 {
+			name = hookJobName(dc.GetName(), strings.TrimSuffix(hook.Params, "Params")+"-"+hook.Phase)
+		}
+		job, err := buildHookJob(dc, deployment, hook, name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to convert %s %s hook: %w", hook.Params, hook.Phase, err)
+		}
+		jobs = append(jobs, job)
+
+		notes = append(notes, newFinding(codeHook, hookField(hook)+".execNewPod",
+			fmt.Sprintf("%s %s hook converted to Job %s (%s)", hook.Params, hook.Phase, job.GetName(), hookPhaseAnnotations[hook.Phase]["argocd.argoproj.io/hook"])))
+		if hook.Phase == "mid" {
+			notes = append(notes, newFinding(codeHook, hookField(hook),
+				fmt.Sprintf("%s mid hook runs as the last pre-sync hook; it no longer runs after the old pods are scaled down", hook.Params)))
+		}
+		switch hook.FailurePolicy {
+		case "Retry":
+			notes = append(notes, newFinding(codeHook, hookField(hook)+".failurePolicy",
+				fmt.Sprintf("%s %s hook failurePolicy Retry mapped to backoffLimit %d; the DC controller retried until the rollout timed out", hook.Params, hook.Phase, hookFailurePolicyBackoffLimits["Retry"])))
+		case "Ignore":
+			notes = append(notes, newFinding(codeHook, hookField(hook)+".failurePolicy",
+				fmt.Sprintf("%s %s hook failurePolicy Ignore mapped to backoffLimit 0; a failed hook Job will still block the sync", hook.Params, hook.Phase)))
+		}
+	}
+
+	return jobs, notes, nil
+}
+
+func buildHookJob(dc, deployment *unstructured.Unstructured, hook lifecycleHook, name string) (*unstructured.Unstructured, error) {
+	containerName, _, _ := unstructured.NestedString(hook.ExecNewPod, "containerName")
+	podSpec, found, err := unstructured.NestedMap(deployment.Object, "spec", "template", "spec")
+	if err != nil {
+		return nil, fmt.Errorf("error getting pod template: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("pod template not found in Deployment")
+	}
+
+	var base map[string]interface{}
+	containers, _, _ := unstructured.NestedSlice(podSpec, "containers")
+	for _, c := range containers {
+		if container, ok := c.(map[string]interface{}); ok && container["name"] == containerName {
+			base = container
+			break
+		}
+	}
+	if base == nil {
+		return nil, fmt.Errorf("container %q not found in pod template", containerName)
+	}
+
+	hookVolumes, _, _ := unstructured.NestedStringSlice(hook.ExecNewPod, "volumes")
+
+	container := map[string]interface{}{
+		"name":  containerName,
+		"image": base["image"],
+	}
+	if command, found, _ := unstructured.NestedSlice(hook.ExecNewPod, "command"); found && len(command) > 0 {
+		container["command"] = command
+	} else {
+		for _, field := range []string{"command", "args"} {
+			if value, ok := base[field]; ok {
+				container[field] = value
+			}
+		}
+	}
+	for _, field := range []string{"workingDir", "envFrom", "resources", "securityContext"} {
+		if value, ok := base[field]; ok {
+			container[field] = value
+		}
+	}
+	if env := mergeHookEnv(base, hook.ExecNewPod); len(env) > 0 {
+		container["env"] = env
+	}
+	if mounts := filterByName(base["volumeMounts"], hookVolumes); len(mounts) > 0 {
+		container["volumeMounts"] = mounts
+	}
+
+	jobPodSpec := map[string]interface{}{
+		"restartPolicy": "Never",
+		"containers":    []interface{}{container},
+	}
+	for _, field := range []string{"serviceAccountName", "imagePullSecrets", "securityContext", "nodeSelector", "tolerations"} {
+		if value, ok := podSpec[field]; ok {
+			jobPodSpec[field] = value
+		}
+	}
+	if volumes := filterByName(podSpec["volumes"], hookVolumes); len(volumes) > 0 {
+		jobPodSpec["volumes"] = volumes
+	}
+
+	annotations := map[string]interface{}{
+		generatedByAnnotation:                   generatedByValue,
+		"argocd.argoproj.io/hook-delete-policy": "BeforeHookCreation",
+		"helm.sh/hook-delete-policy":            "before-hook-creation",
+	}
+	for k, v := range hookPhaseAnnotations[hook.Phase] {
+		annotations[k] = v
+	}
+
+	backoffLimit, ok := hookFailurePolicyBackoffLimits[hook.FailurePolicy]
+	if !ok {
+		backoffLimit = hookFailurePolicyBackoffLimits["Abort"]
+	}
+
+	metadata := map[string]interface{}{
+		"name":        name,
+		"annotations": annotations,
+	}
+	if namespace := deployment.GetNamespace(); namespace != "" {
+		metadata["namespace"] = namespace
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata":   metadata,
+			"spec": map[string]interface{}{
+				"backoffLimit": backoffLimit,
+				"template": map[string]interface{}{
+					"spec": jobPodSpec,
+				},
+			},
+		},
+	}, nil
+}
+
+// hookJobName returns "<dc>-<phase>-hook", truncating the DC name so that the
+// result stays within the 63 character limit of the job-name label.
+func hookJobName(dcName, phase string) string {
+	suffix := fmt.Sprintf("-%s-hook", phase)
+	if len(dcName)+len(suffix) > 63 {
+		dcName = dcName[:63-len(suffix)]
+	}
+	return dcName + suffix
+}
+
+// mergeHookEnv returns the container env with the hook's env entries
+// overriding or appended to it, as the DC controller does for hook pods.
+func mergeHookEnv(container, execNewPod map[string]interface{}) []interface{} {
+	baseEnv, _ := container["env"].([]interface{})
+	hookEnv, _, _ := unstructured.NestedSlice(execNewPod, "env")
+
+	overrides := map[string]interface{}{}
+	for _, e := range hookEnv {
+		if entry, ok := e.(map[string]interface{}); ok {
+			if name, ok := entry["name"].(string); ok {
+				overrides[name] = entry
+			}
+		}
+	}
+
+	var env []interface{}
+	for _, e := range baseEnv {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, ok := entry["name"].(string); ok {
+			if _, overridden := overrides[name]; overridden {
+				continue
+			}
+		}
+		env = append(env, entry)
+	}
+	return append(env, hookEnv...)
+}
+
+// filterByName keeps the named entries (volumes or volumeMounts) whose name is
+// listed in names.
+func filterByName(items interface{}, names []string) []interface{} {
+	list, _ := items.([]interface{})
+	var filtered []interface{}
+	for _, item := range list {
+		if entry, ok := item.(map[string]interface{}); ok {
+			if name, ok := entry["name"].(string); ok && contains(names, name) {
+				filtered = append(filtered, entry)
+			}
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newHookedDC(overrides ...func(*unstructured.Unstructured)) *unstructured.Unstructured {
+	return newTestDC(append([]func(*unstructured.Unstructured){
+		withField(map[string]interface{}{
+			"type": "Recreate",
+			"recreateParams": map[string]interface{}{
+				"pre": map[string]interface{}{
+					"failurePolicy": "Abort",
+					"execNewPod": map[string]interface{}{
+						"containerName": "web",
+						"command":       []interface{}{"/bin/migrate", "--up"},
+						"env": []interface{}{
+							map[string]interface{}{"name": "MODE", "value": "hook"},
+						},
+						"volumes": []interface{}{"config"},
+					},
+				},
+				"mid": map[string]interface{}{
+					"failurePolicy": "Ignore",
+					"tagImages": []interface{}{
+						map[string]interface{}{"containerName": "web"},
+					},
+				},
+				"post": map[string]interface{}{
+					"failurePolicy": "Retry",
+					"execNewPod": map[string]interface{}{
+						"containerName": "web",
+						"command":       []interface{}{"/bin/notify"},
+					},
+				},
+			},
+		}, "spec", "strategy"),
+		withField("web", "spec", "template", "spec", "serviceAccountName"),
+		withField([]interface{}{
+			map[string]interface{}{
+				"name":  "web",
+				"image": "registry/web@sha256:abc",
+				"env": []interface{}{
+					map[string]interface{}{"name": "MODE", "value": "server"},
+					map[string]interface{}{"name": "PORT", "value": "8080"},
+				},
+				"volumeMounts": []interface{}{
+					map[string]interface{}{"name": "config", "mountPath": "/config"},
+					map[string]interface{}{"name": "data", "mountPath": "/data"},
+				},
+			},
+		}, "spec", "template", "spec", "containers"),
+		withField([]interface{}{
+			map[string]interface{}{"name": "config", "configMap": map[string]interface{}{"name": "web"}},
+			map[string]interface{}{"name": "data", "emptyDir": map[string]interface{}{}},
+		}, "spec", "template", "spec", "volumes"),
+	}, overrides...)...)
+}
+
+func TestConvertHooksToJobs(t *testing.T) {
+	dc := newHookedDC()
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	jobs, notes, err := convertHooksToJobs(dc, deployment)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 2)
+	assert.Len(t, notes, 4)
+	assert.Equal(t, "spec.strategy.recreateParams.mid.tagImages", notes[1].Field)
+	assert.Equal(t, SeverityWarning, notes[1].Severity)
+
+	pre := jobs[0]
+	assert.Equal(t, "Job", pre.GetKind())
+	assert.Equal(t, "test-dc-pre-hook", pre.GetName())
+	assert.Equal(t, "test-namespace", pre.GetNamespace())
+	assert.Equal(t, "PreSync", pre.GetAnnotations()["argocd.argoproj.io/hook"])
+	assert.Equal(t, "pre-install,pre-upgrade", pre.GetAnnotations()["helm.sh/hook"])
+
+	backoffLimit, _, _ := unstructured.NestedInt64(pre.Object, "spec", "backoffLimit")
+	assert.Equal(t, int64(0), backoffLimit)
+
+	podSpec, _, _ := unstructured.NestedMap(pre.Object, "spec", "template", "spec")
+	assert.Equal(t, "Never", podSpec["restartPolicy"])
+	assert.Equal(t, "web", podSpec["serviceAccountName"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "config", "configMap": map[string]interface{}{"name": "web"}},
+	}, podSpec["volumes"])
+
+	container := podSpec["containers"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "registry/web@sha256:abc", container["image"])
+	assert.Equal(t, []interface{}{"/bin/migrate", "--up"}, container["command"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "PORT", "value": "8080"},
+		map[string]interface{}{"name": "MODE", "value": "hook"},
+	}, container["env"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "config", "mountPath": "/config"},
+	}, container["volumeMounts"])
+
+	post := jobs[1]
+	assert.Equal(t, "test-dc-post-hook", post.GetName())
+	assert.Equal(t, "PostSync", post.GetAnnotations()["argocd.argoproj.io/hook"])
+	backoffLimit, _, _ = unstructured.NestedInt64(post.Object, "spec", "backoffLimit")
+	assert.Equal(t, int64(6), backoffLimit)
+	_, found, _ := unstructured.NestedSlice(post.Object, "spec", "template", "spec", "volumes")
+	assert.False(t, found)
+}
+
+func TestConvertHooksToJobsSkipsUnusedParams(t *testing.T) {
+	dc := newHookedDC()
+	preHook, _, _ := unstructured.NestedMap(dc.Object, "spec", "strategy", "recreateParams", "pre")
+	assert.NoError(t, unstructured.SetNestedMap(dc.Object, preHook, "spec", "strategy", "rollingParams", "pre"))
+	assert.NoError(t, unstructured.SetNestedStringSlice(dc.Object, []string{"/bin/rolling"}, "spec", "strategy", "rollingParams", "pre", "execNewPod", "command"))
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	jobs, _, err := convertHooksToJobs(dc, deployment)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 2)
+	assert.Equal(t, "test-dc-pre-hook", jobs[0].GetName())
+	containers, _, _ := unstructured.NestedSlice(jobs[0].Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, []interface{}{"/bin/migrate", "--up"}, containers[0].(map[string]interface{})["command"])
+
+	// A Custom strategy keeps both, under distinct names.
+	assert.NoError(t, unstructured.SetNestedField(dc.Object, "Custom", "spec", "strategy", "type"))
+	jobs, _, err = convertHooksToJobs(dc, deployment)
+	assert.NoError(t, err)
+	var names []string
+	for _, job := range jobs {
+		names = append(names, job.GetName())
+	}
+	assert.Equal(t, []string{"test-dc-recreate-pre-hook", "test-dc-post-hook", "test-dc-rolling-pre-hook"}, names)
+}
+
+func TestConvertHooksToJobsMissingContainer(t *testing.T) {
+	dc := newHookedDC()
+	assert.NoError(t, unstructured.SetNestedField(dc.Object, "missing", "spec", "strategy", "recreateParams", "pre", "execNewPod", "containerName"))
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	_, _, err = convertHooksToJobs(dc, deployment)
+	assert.Error(t, err)
+}
+
+func TestHookJobName(t *testing.T) {
+	assert.Equal(t, "web-pre-hook", hookJobName("web", "pre"))
+	assert.Len(t, hookJobName("a-very-long-deployment-config-name-that-exceeds-the-label-limit", "post"), 63)
+}
+
+func TestAnalyzeLifecycleHooks(t *testing.T) {
+	dc := newHookedDC()
+	assert.NoError(t, unstructured.SetNestedMap(dc.Object, map[string]interface{}{
+		"failurePolicy": "Abort",
+	}, "spec", "strategy", "rollingParams", "pre"))
+
+	hooks, warnings := analyzeLifecycleHooks(dc)
+	assert.Equal(t, []HookSummary{
+		{Params: "recreateParams", Phase: "pre", Actions: []string{"execNewPod"}, FailurePolicy: "Abort"},
+		{Params: "recreateParams", Phase: "mid", Actions: []string{"tagImages"}, FailurePolicy: "Ignore"},
+		{Params: "recreateParams", Phase: "post", Actions: []string{"execNewPod"}, FailurePolicy: "Retry"},
+		{Params: "rollingParams", Phase: "pre", FailurePolicy: "Abort"},
+	}, hooks)
+	assert.Equal(t, []Finding{
+		newFinding(codeHook, "spec.strategy.rollingParams.pre", "rollingParams pre hook has no execNewPod or tagImages action").withSeverity(SeverityWarning),
+		newFinding(codeHook, "spec.strategy.rollingParams.pre", "rollingParams pre hook is not run by the Recreate strategy and is not converted").withSeverity(SeverityWarning),
+	}, warnings)
+
+	assert.Equal(t, "execNewPod, empty", hookActions(hooks, "pre"))
+	assert.Equal(t, "tagImages", hookActions(hooks, "mid"))
+	assert.Equal(t, "-", hookActions(nil, "post"))
+}
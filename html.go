@@ -0,0 +1,383 @@
+package main
+
+import (
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// htmlReportWriter writes a single static HTML page with the outcome totals
+// of each namespace, a table of the DeploymentConfigs that can be sorted and
+// filtered by namespace, outcome, status and finding, and a side-by-side view
+// of each DeploymentConfig and its Deployment. The styles and scripts are
+// inline, so the page can be shared as one file.
+type htmlReportWriter struct{}
+
+func (htmlReportWriter) Extension() string { return ".html" }
+
+// maxDiffCells bounds the size of the table used to diff two manifests; larger
+// manifests are shown side by side without highlighting.
+const maxDiffCells = 4000000
+
+// diffLine is a line of a manifest, marked when it is not in the other one.
+type diffLine struct {
+	Text    string
+	Changed bool
+}
+
+type htmlRow struct {
+	ID           int
+	Namespace    string
+	Name         string
+	Outcome      string
+	Error        string
+	Status       string
+	Errors       int
+	Warnings     int
+	Codes        []string
+	ApplyOutcome string
+	SkipReason   string
+	Findings     []Finding
+	Source       []diffLine
+	Deployment   []diffLine
+}
+
+type htmlPage struct {
+	Report     *Report
+	Totals     []namespaceTotals
+	Sum        namespaceTotals
+	Rows       []htmlRow
+	Namespaces []string
+	Outcomes   []string
+	Statuses   []string
+	Codes      []string
+}
+
+// conversionStatus sums up a ConversionInfo for the HTML report: skipped, or
+// the highest severity of its findings, or ok.
+func conversionStatus(info ConversionInfo) string {
+	if info.SkipReason != "" {
+		return "skipped"
+	}
+	status := "ok"
+	for _, finding := range info.Findings {
+		switch finding.Severity {
+		case SeverityError:
+			return "error"
+		case SeverityWarning:
+			status = "warning"
+		}
+	}
+	return status
+}
+
+func (htmlReportWriter) Write(w io.Writer, report *Report) error {
+	page := htmlPage{Report: report, Totals: totalsByNamespace(report.Conversions)}
+	page.Sum = sumTotals(page.Totals)
+	namespaces, statuses, codes := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	seenOutcomes := map[Outcome]bool{}
+	for i, info := range report.Conversions {
+		row := htmlRow{
+			ID:           i,
+			Namespace:    info.Namespace,
+			Name:         displayName(info),
+			Outcome:      string(info.Outcome),
+			Error:        info.Error,
+			Status:       conversionStatus(info),
+			ApplyOutcome: string(info.ApplyOutcome),
+			SkipReason:   info.SkipReason,
+			Findings:     info.Findings,
+		}
+		rowCodes := map[string]bool{}
+		for _, finding := range info.Findings {
+			switch finding.Severity {
+			case SeverityError:
+				row.Errors++
+			case SeverityWarning:
+				row.Warnings++
+			}
+			if !rowCodes[finding.Code] {
+				rowCodes[finding.Code] = true
+				row.Codes = append(row.Codes, finding.Code)
+			}
+			codes[finding.Code] = true
+		}
+		row.Source, row.Deployment = diffLines(info.SourceManifest, info.DeploymentManifest)
+		namespaces[info.Namespace] = true
+		seenOutcomes[info.Outcome] = true
+		statuses[row.Status] = true
+		page.Rows = append(page.Rows, row)
+	}
+	page.Namespaces = sortedKeys(namespaces)
+	for _, outcome := range outcomes {
+		if seenOutcomes[outcome] {
+			page.Outcomes = append(page.Outcomes, string(outcome))
+		}
+	}
+	page.Statuses = sortedKeys(statuses)
+	page.Codes = sortedKeys(codes)
+	return htmlReportTemplate.Execute(w, page)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffLines splits both manifests into lines and marks the lines that are not
+// part of their longest common subsequence: the lines dropped from the
+// DeploymentConfig and the lines added to the Deployment.
+func diffLines(before, after string) ([]diffLine, []diffLine) {
+	a, b := splitLines(before), splitLines(after)
+	left, right := make([]diffLine, len(a)), make([]diffLine, len(b))
+	for i, line := range a {
+		left[i] = diffLine{Text: line}
+	}
+	for j, line := range b {
+		right[j] = diffLine{Text: line}
+	}
+	if len(a)*len(b) > maxDiffCells {
+		return left, right
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			left[i].Changed = true
+			i++
+		default:
+			right[j].Changed = true
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		left[i].Changed = true
+	}
+	for ; j < len(b); j++ {
+		right[j].Changed = true
+	}
+	return left, right
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>DeploymentConfig Migration Report {{.Report.RunID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.5em; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 0.2em 1em; }
+dt { font-weight: bold; }
+.filters { margin: 1em 0; display: flex; gap: 1em; flex-wrap: wrap; }
+table.conversions { border-collapse: collapse; width: 100%; }
+table.conversions th, table.conversions td { border: 1px solid #ccc; padding: 0.3em 0.5em; text-align: left; vertical-align: top; }
+table.conversions th { background: #eee; cursor: pointer; user-select: none; }
+table.conversions th[aria-sort=ascending]::after { content: " \25B2"; }
+table.conversions th[aria-sort=descending]::after { content: " \25BC"; }
+tr.conversion { cursor: pointer; }
+tr.conversion:hover { background: #f5f5f5; }
+tr.details > td { background: #fafafa; }
+.status { font-weight: bold; }
+.status-error { color: #b00020; }
+.status-warning { color: #b36b00; }
+.status-ok { color: #2e7d32; }
+.status-skipped { color: #777; }
+.outcome-failed, .outcome-panicked { color: #b00020; font-weight: bold; }
+table.totals { border-collapse: collapse; margin-bottom: 1em; }
+table.totals th, table.totals td { border: 1px solid #ccc; padding: 0.3em 0.8em; }
+table.totals td.count { text-align: right; }
+.manifests { display: grid; grid-template-columns: 1fr 1fr; gap: 1em; }
+.manifests pre { margin: 0; padding: 0.5em; background: #fff; border: 1px solid #ddd; overflow-x: auto; font-size: 0.85em; }
+.manifests span { display: block; min-height: 1em; }
+.removed { background: #fdd; }
+.added { background: #dfd; }
+</style>
+</head>
+<body>
+<h1>DeploymentConfig Migration Report</h1>
+<dl>
+<dt>Run ID</dt><dd>{{.Report.RunID}}</dd>
+{{- if .Report.BackupLocation}}
+<dt>Backup location</dt><dd>{{.Report.BackupLocation}}</dd>
+{{- end}}
+{{- if .Report.ProjectsInScope}}
+<dt>Projects in scope</dt><dd>{{join .Report.ProjectsInScope ", "}}{{if .Report.ProjectScopeSource}} ({{.Report.ProjectScopeSource}}){{end}}</dd>
+{{- end}}
+<dt>Allow invalid</dt><dd>{{.Report.AllowInvalid}}</dd>
+</dl>
+{{- if .Report.SkippedInputObjects}}
+<h2>Skipped Input Objects</h2>
+<ul>
+{{- range .Report.SkippedInputObjects}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+
+<h2>Outcomes per Namespace</h2>
+<table class="totals">
+<tr><th>Namespace</th><th>Succeeded</th><th>Failed</th><th>Skipped</th></tr>
+{{- range .Totals}}
+<tr><td>{{.Namespace}}</td><td class="count">{{.Succeeded}}</td><td class="count">{{.Failed}}</td><td class="count">{{.Skipped}}</td></tr>
+{{- end}}
+<tr><th>Total</th><th>{{.Sum.Succeeded}}</th><th>{{.Sum.Failed}}</th><th>{{.Sum.Skipped}}</th></tr>
+</table>
+
+<h2>DeploymentConfigs</h2>
+<div class="filters">
+<label>Namespace <select id="filter-namespace"><option value="">All</option>{{range .Namespaces}}<option>{{.}}</option>{{end}}</select></label>
+<label>Outcome <select id="filter-outcome"><option value="">All</option>{{range .Outcomes}}<option>{{.}}</option>{{end}}</select></label>
+<label>Status <select id="filter-status"><option value="">All</option>{{range .Statuses}}<option>{{.}}</option>{{end}}</select></label>
+<label>Finding <select id="filter-code"><option value="">All</option>{{range .Codes}}<option>{{.}}</option>{{end}}</select></label>
+<label>Search <input id="filter-text" type="search"></label>
+<span id="filter-count"></span>
+</div>
+<table class="conversions">
+<thead>
+<tr>
+<th data-key="namespace">Namespace</th>
+<th data-key="name">DeploymentConfig</th>
+<th data-key="outcome">Outcome</th>
+<th data-key="status">Status</th>
+<th data-key="errors" data-numeric>Errors</th>
+<th data-key="warnings" data-numeric>Warnings</th>
+<th data-key="codes">Findings</th>
+<th data-key="apply">Apply Outcome</th>
+</tr>
+</thead>
+{{- range .Rows}}
+<tbody data-namespace="{{.Namespace}}" data-name="{{.Name}}" data-outcome="{{.Outcome}}" data-status="{{.Status}}" data-errors="{{.Errors}}" data-warnings="{{.Warnings}}" data-codes="{{join .Codes " "}}" data-apply="{{.ApplyOutcome}}">
+<tr class="conversion" aria-controls="details-{{.ID}}">
+<td>{{.Namespace}}</td>
+<td>{{.Name}}</td>
+<td class="outcome-{{.Outcome}}">{{.Outcome}}</td>
+<td class="status status-{{.Status}}">{{.Status}}</td>
+<td>{{.Errors}}</td>
+<td>{{.Warnings}}</td>
+<td>{{join .Codes ", "}}</td>
+<td>{{.ApplyOutcome}}</td>
+</tr>
+<tr class="details" id="details-{{.ID}}" hidden>
+<td colspan="8">
+{{- if .Error}}
+<p>{{.Outcome}}: {{.Error}}</p>
+{{- end}}
+{{- if .SkipReason}}
+<p>Skipped: {{.SkipReason}}</p>
+{{- end}}
+{{- if .Findings}}
+<ul>
+{{- range .Findings}}
+<li><code>{{.Code}}</code> [{{.Severity}}] {{.Field}}: {{.Message}}<br><em>What to do:</em> {{.Remediation}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if or .Source .Deployment}}
+<details>
+<summary>DeploymentConfig and Deployment</summary>
+<div class="manifests">
+<pre>{{range .Source}}<span{{if .Changed}} class="removed"{{end}}>{{.Text}}</span>{{end}}</pre>
+<pre>{{range .Deployment}}<span{{if .Changed}} class="added"{{end}}>{{.Text}}</span>{{end}}</pre>
+</div>
+</details>
+{{- end}}
+</td>
+</tr>
+</tbody>
+{{- end}}
+</table>
+<script>
+(function () {
+  var table = document.querySelector("table.conversions");
+  var groups = Array.prototype.slice.call(table.tBodies);
+  var filters = {
+    namespace: document.getElementById("filter-namespace"),
+    outcome: document.getElementById("filter-outcome"),
+    status: document.getElementById("filter-status"),
+    code: document.getElementById("filter-code"),
+    text: document.getElementById("filter-text")
+  };
+
+  function applyFilters() {
+    var text = filters.text.value.toLowerCase();
+    var shown = 0;
+    groups.forEach(function (group) {
+      var d = group.dataset;
+      var visible = (!filters.namespace.value || d.namespace === filters.namespace.value) &&
+        (!filters.outcome.value || d.outcome === filters.outcome.value) &&
+        (!filters.status.value || d.status === filters.status.value) &&
+        (!filters.code.value || d.codes.split(" ").indexOf(filters.code.value) >= 0) &&
+        (!text || group.textContent.toLowerCase().indexOf(text) >= 0);
+      group.hidden = !visible;
+      if (visible) { shown++; }
+    });
+    document.getElementById("filter-count").textContent = shown + " of " + groups.length + " shown";
+  }
+  Object.keys(filters).forEach(function (key) {
+    filters[key].addEventListener("input", applyFilters);
+  });
+  applyFilters();
+
+  table.querySelectorAll("th").forEach(function (th) {
+    th.addEventListener("click", function () {
+      var key = th.dataset.key;
+      var numeric = th.hasAttribute("data-numeric");
+      var dir = th.getAttribute("aria-sort") === "ascending" ? -1 : 1;
+      table.querySelectorAll("th").forEach(function (other) { other.removeAttribute("aria-sort"); });
+      th.setAttribute("aria-sort", dir === 1 ? "ascending" : "descending");
+      groups.sort(function (a, b) {
+        var x = a.dataset[key], y = b.dataset[key];
+        var c = numeric ? Number(x) - Number(y) : x.localeCompare(y);
+        return c * dir;
+      });
+      groups.forEach(function (group) { table.appendChild(group); });
+    });
+  });
+
+  table.querySelectorAll("tr.conversion").forEach(function (row) {
+    row.addEventListener("click", function () {
+      var details = document.getElementById(row.getAttribute("aria-controls"));
+      details.hidden = !details.hidden;
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`))
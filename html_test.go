@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversionStatus(t *testing.T) {
+	report := newTestReport()
+	assert.Equal(t, "ok", conversionStatus(ConversionInfo{}))
+	assert.Equal(t, "warning", conversionStatus(report.Conversions[0]))
+	assert.Equal(t, "error", conversionStatus(report.Conversions[1]))
+	assert.Equal(t, "skipped", conversionStatus(report.Conversions[2]))
+}
+
+func TestDiffLines(t *testing.T) {
+	left, right := diffLines("kind: DeploymentConfig\nspec:\n  replicas: 2\n  triggers: []\n", "kind: Deployment\nspec:\n  replicas: 2\n")
+
+	assert.Equal(t, []diffLine{
+		{Text: "kind: DeploymentConfig", Changed: true},
+		{Text: "spec:"},
+		{Text: "  replicas: 2"},
+		{Text: "  triggers: []", Changed: true},
+	}, left)
+	assert.Equal(t, []diffLine{
+		{Text: "kind: Deployment", Changed: true},
+		{Text: "spec:"},
+		{Text: "  replicas: 2"},
+	}, right)
+
+	left, right = diffLines("", "kind: Deployment\n")
+	assert.Empty(t, left)
+	assert.Equal(t, []diffLine{{Text: "kind: Deployment", Changed: true}}, right)
+}
+
+func TestHTMLReportWriter(t *testing.T) {
+	report := newTestReport()
+	report.Conversions[0].SourceManifest = "kind: DeploymentConfig\nmetadata:\n  name: <test-dc>\n"
+	report.Conversions[0].DeploymentManifest = "kind: Deployment\nmetadata:\n  name: <test-dc>\n"
+
+	var out bytes.Buffer
+	assert.NoError(t, htmlReportWriter{}.Write(&out, report))
+
+	page := out.String()
+	assert.Contains(t, page, "<title>DeploymentConfig Migration Report 20240816-134703</title>")
+	assert.NotContains(t, page, "<link")
+	assert.NotContains(t, page, "src=")
+	assert.Contains(t, page, `<option>other-namespace</option><option>test-namespace</option>`)
+	assert.Contains(t, page, `<option>error</option><option>skipped</option><option>warning</option>`)
+	assert.Contains(t, page, `data-codes="triggers hook unmapped-field"`)
+	assert.Contains(t, page, `<span class="removed">kind: DeploymentConfig</span>`)
+	assert.Contains(t, page, `<span class="added">kind: Deployment</span>`)
+	assert.Contains(t, page, "<span>  name: &lt;test-dc&gt;</span>")
+	assert.Contains(t, page, "Skipped: annotated with migration.openshift.io/skip=true")
+	assert.Contains(t, page, `<option>converted</option><option>failed</option><option>skipped</option>`)
+	assert.Contains(t, page, "<p>failed: error applying Deployment custom-dc: conflict</p>")
+	assert.Contains(t, page, `<tr><td>test-namespace</td><td class="count">1</td><td class="count">1</td><td class="count">0</td></tr>`)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// ImageResolution records how the image of a single container was resolved.
+type ImageResolution struct {
+	Container string `json:"container"`
+	Field     string `json:"field"`
+	Original  string `json:"original"`
+	Resolved  string `json:"resolved"`
+	Source    string `json:"source"`
+}
+
+var (
+	imageStreamRes           = schema.GroupVersionResource{Group: "image.openshift.io", Version: "v1", Resource: "imagestreams"}
+	replicationControllerRes = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "replicationcontrollers"}
+)
+
+// isPlaceholderImage reports whether an image cannot be pulled as it is: it is
+// blank, or a bare ImageStream name that the ImageChange trigger fills in.
+func isPlaceholderImage(image string) bool {
+	image = strings.TrimSpace(image)
+	return image == "" || !strings.ContainsAny(image, "/:@")
+}
+
+// resolveImages replaces placeholder and ImageStream-driven container images
+// in the Deployment with concrete, digest-pinned pull specs. Images are looked
+// up from the ImageStreamTag referenced by the DC's ImageChange trigger first,
+// falling back to the latest successful ReplicationController of the DC,
+// whose image may only be a tag. Without a client (offline mode) only
+// placeholder images are reported as unresolved; concrete images are kept.
+func resolveImages(client dynamic.Interface, dc, deployment *unstructured.Unstructured) ([]ImageResolution, error) {
+	triggers, err := getImageChangeTriggers(dc)
+	if err != nil {
+		return nil, err
+	}
+	triggerByContainer := map[string]imageChangeTrigger{}
+	for _, trigger := range triggers {
+		for _, name := range trigger.ContainerNames {
+			triggerByContainer[name] = trigger
+		}
+	}
+
+	var rcImages map[string]string
+	var rcName string
+	var resolutions []ImageResolution
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, found, err := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", field)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s: %w", field, err)
+		}
+		if !found {
+			continue
+		}
+
+		for i, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := container["name"].(string)
+			image, _ := container["image"].(string)
+			resolution := ImageResolution{Container: name, Field: containerImageField(field, name), Original: image, Resolved: image, Source: "unchanged"}
+
+			trigger, triggered := triggerByContainer[name]
+			if isDigestPinned(image) || (!triggered && strings.TrimSpace(image) != "") {
+				resolutions = append(resolutions, resolution)
+				continue
+			}
+
+			if client == nil {
+				if isPlaceholderImage(image) {
+					resolution.Source = "unresolved"
+				}
+				resolutions = append(resolutions, resolution)
+				continue
+			}
+
+			resolved := ""
+			if triggered && trigger.FromKind == "ImageStreamTag" {
+				namespace := trigger.FromNamespace
+				if namespace == "" {
+					namespace = dc.GetNamespace()
+				}
+				ref, err := imageStreamTagReference(client, namespace, trigger.FromName)
+				if err != nil {
+					return nil, err
+				}
+				if ref != "" {
+					resolved = ref
+					resolution.Source = fmt.Sprintf("ImageStreamTag %s/%s", namespace, trigger.FromName)
+				}
+			}
+
+			if resolved == "" {
+				if rcImages == nil {
+					rcName, rcImages, err = latestReplicationControllerImages(client, dc)
+					if err != nil {
+						return nil, err
+					}
+				}
+				if ref := rcImages[name]; strings.TrimSpace(ref) != "" {
+					resolved = ref
+					resolution.Source = fmt.Sprintf("ReplicationController %s", rcName)
+				}
+			}
+
+			if resolved == "" {
+				resolution.Source = "unresolved"
+				resolutions = append(resolutions, resolution)
+				continue
+			}
+
+			container["image"] = resolved
+			containers[i] = container
+			resolution.Resolved = resolved
+			resolutions = append(resolutions, resolution)
+		}
+
+		if err := unstructured.SetNestedSlice(deployment.Object, containers, "spec", "template", "spec", field); err != nil {
+			return nil, fmt.Errorf("error setting %s: %w", field, err)
+		}
+	}
+
+	return resolutions, nil
+}
+
+// imageStreamTagReference returns the dockerImageReference of the most recent
+// image of an ImageStreamTag ("stream:tag"), or an empty string if the tag has
+// no image yet.
+func imageStreamTagReference(client dynamic.Interface, namespace, istag string) (string, error) {
+	stream, tag, found := strings.Cut(istag, ":")
+	if !found {
+		tag = "latest"
+	}
+
+	ctx := context.Background()
+	imageStream, err := client.Resource(imageStreamRes).Namespace(namespace).Get(ctx, stream, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("error getting ImageStream %s in namespace %s: %w", stream, namespace, err)
+	}
+
+	tags, _, _ := unstructured.NestedSlice(imageStream.Object, "status", "tags")
+	for _, t := range tags {
+		tagStatus, ok := t.(map[string]interface{})
+		if !ok || tagStatus["tag"] != tag {
+			continue
+		}
+		items, _, _ := unstructured.NestedSlice(tagStatus, "items")
+		if len(items) == 0 {
+			return "", nil
+		}
+		if item, ok := items[0].(map[string]interface{}); ok {
+			ref, _, _ := unstructured.NestedString(item, "dockerImageReference")
+			return ref, nil
+		}
+	}
+	return "", nil
+}
+
+// latestReplicationControllerImages returns the container images of the most
+// recent successfully completed ReplicationController created for the DC.
+func latestReplicationControllerImages(client dynamic.Interface, dc *unstructured.Unstructured) (string, map[string]string, error) {
+	ctx := context.Background()
+	rcList, err := client.Resource(replicationControllerRes).Namespace(dc.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("openshift.io/deployment-config.name=%s", dc.GetName()),
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("error listing ReplicationControllers for %s: %w", dc.GetName(), err)
+	}
+
+	var latest *unstructured.Unstructured
+	latestVersion := int64(-1)
+	for i := range rcList.Items {
+		rc := &rcList.Items[i]
+		annotations := rc.GetAnnotations()
+		if annotations["openshift.io/deployment.phase"] != "Complete" {
+			continue
+		}
+		version, err := strconv.ParseInt(annotations["openshift.io/deployment-config.latest-version"], 10, 64)
+		if err != nil {
+			continue
+		}
+		if version > latestVersion {
+			latest, latestVersion = rc, version
+		}
+	}
+
+	images := map[string]string{}
+	if latest == nil {
+		return "", images, nil
+	}
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(latest.Object, "spec", "template", "spec", field)
+		for _, c := range containers {
+			if container, ok := c.(map[string]interface{}); ok {
+				name, _ := container["name"].(string)
+				image, _ := container["image"].(string)
+				images[name] = image
+			}
+		}
+	}
+	return latest.GetName(), images, nil
+}
+
+// containerImageField returns the path of the image of the named container
+// in the containers or initContainers of the pod template.
+func containerImageField(field, name string) string {
+	return fmt.Sprintf("spec.template.spec.%s[name=%s].image", field, name)
+}
+
+func isDigestPinned(image string) bool {
+	return strings.Contains(image, "@sha256:")
+}
+
+// unpinnedImages lists the containers whose image was resolved from a
+// ReplicationController to a tag rather than a digest.
+func unpinnedImages(resolutions []ImageResolution) []Finding {
+	var followUps []Finding
+	for _, resolution := range resolutions {
+		if strings.HasPrefix(resolution.Source, "ReplicationController ") && !isDigestPinned(resolution.Resolved) {
+			followUps = append(followUps, newFinding(codeUnpinnedImage, resolution.Field,
+				fmt.Sprintf("Image %q of container %s was taken from %s and is not pinned by digest", resolution.Resolved, resolution.Container, resolution.Source)))
+		}
+	}
+	return followUps
+}
+
+// unresolvedImages lists the containers whose image could not be resolved.
+func unresolvedImages(resolutions []ImageResolution) []Finding {
+	var followUps []Finding
+	for _, resolution := range resolutions {
+		if resolution.Source == "unresolved" {
+			followUps = append(followUps, newFinding(codeUnresolvedImage, resolution.Field, fmt.Sprintf("Image %q of container %s could not be resolved from an ImageStreamTag or ReplicationController", resolution.Original, resolution.Container)))
+		}
+	}
+	return followUps
+}
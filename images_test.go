@@ -0,0 +1,177 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newFakeDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		imageStreamRes:           "ImageStreamList",
+		replicationControllerRes: "ReplicationControllerList",
+	}, objects...)
+}
+
+func newReplicationController(name, version, phase, image string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ReplicationController",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "test-namespace",
+				"labels": map[string]interface{}{
+					"openshift.io/deployment-config.name": "test-dc",
+				},
+				"annotations": map[string]interface{}{
+					"openshift.io/deployment-config.latest-version": version,
+					"openshift.io/deployment.phase":                 phase,
+				},
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "web", "image": image},
+						},
+						"initContainers": []interface{}{
+							map[string]interface{}{"name": "init", "image": image},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestResolveImagesFromImageStream(t *testing.T) {
+	imageStream := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "image.openshift.io/v1",
+			"kind":       "ImageStream",
+			"metadata": map[string]interface{}{
+				"name":      "web",
+				"namespace": "images",
+			},
+			"status": map[string]interface{}{
+				"tags": []interface{}{
+					map[string]interface{}{
+						"tag": "latest",
+						"items": []interface{}{
+							map[string]interface{}{"dockerImageReference": "registry/images/web@sha256:abc"},
+							map[string]interface{}{"dockerImageReference": "registry/images/web@sha256:old"},
+						},
+					},
+				},
+			},
+		},
+	}
+	client := newFakeDynamicClient(imageStream)
+
+	dc := newTriggeredDC(true)
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	resolutions, err := resolveImages(client, dc, deployment)
+	assert.NoError(t, err)
+	assert.Equal(t, []ImageResolution{
+		{Container: "init", Field: "spec.template.spec.initContainers[name=init].image", Original: " ", Resolved: "registry/images/web@sha256:abc", Source: "ImageStreamTag images/web:latest"},
+		{Container: "web", Field: "spec.template.spec.containers[name=web].image", Original: " ", Resolved: "registry/images/web@sha256:abc", Source: "ImageStreamTag images/web:latest"},
+	}, resolutions)
+
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, "registry/images/web@sha256:abc", containers[0].(map[string]interface{})["image"])
+}
+
+func TestResolveImagesFromReplicationController(t *testing.T) {
+	client := newFakeDynamicClient(
+		newReplicationController("test-dc-1", "1", "Complete", "registry/test/web@sha256:one"),
+		newReplicationController("test-dc-2", "2", "Complete", "registry/test/web@sha256:two"),
+		newReplicationController("test-dc-3", "3", "Failed", "registry/test/web@sha256:three"),
+	)
+
+	dc := newTriggeredDC(true)
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	resolutions, err := resolveImages(client, dc, deployment)
+	assert.NoError(t, err)
+	for _, resolution := range resolutions {
+		assert.Equal(t, "registry/test/web@sha256:two", resolution.Resolved)
+		assert.Equal(t, "ReplicationController test-dc-2", resolution.Source)
+	}
+	assert.Empty(t, unresolvedImages(resolutions))
+	assert.Empty(t, unpinnedImages(resolutions))
+}
+
+func TestUnpinnedImages(t *testing.T) {
+	resolutions := []ImageResolution{
+		{Container: "web", Field: "spec.template.spec.containers[name=web].image", Resolved: "registry/test/web:v2", Source: "ReplicationController test-dc-2"},
+		{Container: "init", Field: "spec.template.spec.initContainers[name=init].image", Resolved: "registry/test/web@sha256:two", Source: "ReplicationController test-dc-2"},
+		{Container: "sidecar", Field: "spec.template.spec.containers[name=sidecar].image", Resolved: "registry/test/sidecar:v1", Source: "unchanged"},
+	}
+
+	assert.Equal(t, []Finding{
+		newFinding(codeUnpinnedImage, "spec.template.spec.containers[name=web].image", `Image "registry/test/web:v2" of container web was taken from ReplicationController test-dc-2 and is not pinned by digest`),
+	}, unpinnedImages(resolutions))
+}
+
+func TestResolveImagesUnresolved(t *testing.T) {
+	dc := newTriggeredDC(true)
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	resolutions, err := resolveImages(newFakeDynamicClient(), dc, deployment)
+	assert.NoError(t, err)
+	assert.Len(t, unresolvedImages(resolutions), 2)
+}
+
+func TestResolveImagesKeepsConcreteImages(t *testing.T) {
+	dc := newTriggeredDC(true)
+	unstructured.RemoveNestedField(dc.Object, "spec", "triggers")
+	unstructured.RemoveNestedField(dc.Object, "spec", "template", "spec", "initContainers")
+	assert.NoError(t, unstructured.SetNestedSlice(dc.Object, []interface{}{
+		map[string]interface{}{"name": "web", "image": "nginx:1.25"},
+	}, "spec", "template", "spec", "containers"))
+
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	resolutions, err := resolveImages(newFakeDynamicClient(), dc, deployment)
+	assert.NoError(t, err)
+	assert.Equal(t, []ImageResolution{
+		{Container: "web", Field: "spec.template.spec.containers[name=web].image", Original: "nginx:1.25", Resolved: "nginx:1.25", Source: "unchanged"},
+	}, resolutions)
+}
+
+func TestResolveImagesOffline(t *testing.T) {
+	dc := newTriggeredDC(true)
+	assert.NoError(t, unstructured.SetNestedSlice(dc.Object, []interface{}{
+		map[string]interface{}{"name": "init", "image": "web"},
+	}, "spec", "template", "spec", "initContainers"))
+	assert.NoError(t, unstructured.SetNestedSlice(dc.Object, []interface{}{
+		map[string]interface{}{"name": "web", "image": "registry/app:1.0"},
+	}, "spec", "template", "spec", "containers"))
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	resolutions, err := resolveImages(nil, dc, deployment)
+	assert.NoError(t, err)
+	assert.Equal(t, []ImageResolution{
+		{Container: "init", Field: "spec.template.spec.initContainers[name=init].image", Original: "web", Resolved: "web", Source: "unresolved"},
+		{Container: "web", Field: "spec.template.spec.containers[name=web].image", Original: "registry/app:1.0", Resolved: "registry/app:1.0", Source: "unchanged"},
+	}, resolutions)
+}
+
+func TestIsPlaceholderImage(t *testing.T) {
+	assert.True(t, isPlaceholderImage(" "))
+	assert.True(t, isPlaceholderImage("web"))
+	assert.False(t, isPlaceholderImage("web:latest"))
+	assert.False(t, isPlaceholderImage("registry/app:1.0"))
+	assert.False(t, isPlaceholderImage("registry/app@sha256:abc"))
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// offlineDefaultNamespace is the output directory used for input objects that
+// do not set metadata.namespace.
+const offlineDefaultNamespace = "default"
+
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+// readManifests reads every object from the given paths. A path may be a file,
+// a directory (walked recursively for YAML and JSON files) or "-" for stdin.
+// Multi-document YAML and List objects are expanded into their items.
+func readManifests(paths []string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for _, path := range paths {
+		if path == "-" {
+			objs, err := decodeManifests(os.Stdin, "stdin")
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, objs...)
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			objs, err := readManifestFile(path)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, objs...)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !contains(manifestExtensions, strings.ToLower(filepath.Ext(file))) {
+				return nil
+			}
+			objs, err := readManifestFile(file)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, objs...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reading directory %s: %w", path, err)
+		}
+	}
+	return objects, nil
+}
+
+func readManifestFile(path string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return decodeManifests(f, path)
+}
+
+func decodeManifests(r io.Reader, source string) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	var objects []*unstructured.Unstructured
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error decoding %s: %w", source, err)
+		}
+		if len(bytes.TrimSpace(raw)) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw); err != nil {
+			return nil, fmt.Errorf("error decoding object in %s: %w", source, err)
+		}
+
+		if !obj.IsList() {
+			objects = append(objects, obj)
+			continue
+		}
+		list, err := obj.ToList()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding list in %s: %w", source, err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	}
+	return objects, nil
+}
+
+func isDeploymentConfig(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Kind == "DeploymentConfig" && (gvk.Group == "apps.openshift.io" || gvk.Group == "")
+}
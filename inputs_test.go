@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const testDCManifest = `apiVersion: apps.openshift.io/v1
+kind: DeploymentConfig
+metadata:
+  name: web
+  namespace: team-a
+spec:
+  replicas: 2
+  selector:
+    app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+`
+
+const testServiceManifest = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+`
+
+const testListManifest = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {"apiVersion": "apps.openshift.io/v1", "kind": "DeploymentConfig", "metadata": {"name": "api"}, "spec": {"replicas": 1, "selector": {"app": "api"}, "template": {"metadata": {"labels": {"app": "api"}}, "spec": {"containers": [{"name": "api", "image": "api:1"}]}}}},
+    {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "api"}}
+  ]
+}`
+
+func writeTestInputs(t *testing.T) string {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "web.yaml"), []byte(testDCManifest+"---\n"+testServiceManifest), 0600))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "list.json"), []byte(testListManifest), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0600))
+	return dir
+}
+
+func TestReadManifests(t *testing.T) {
+	dir := writeTestInputs(t)
+
+	objects, err := readManifests([]string{dir})
+	assert.NoError(t, err)
+
+	var kinds []string
+	for _, obj := range objects {
+		kinds = append(kinds, obj.GetKind()+"/"+obj.GetName())
+	}
+	assert.ElementsMatch(t, []string{"DeploymentConfig/api", "ConfigMap/api", "DeploymentConfig/web", "Service/web"}, kinds)
+
+	for _, obj := range objects {
+		if obj.GetName() == "web" && isDeploymentConfig(obj) {
+			replicas, _, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), replicas)
+		}
+	}
+}
+
+func TestReadManifestsMissingPath(t *testing.T) {
+	_, err := readManifests([]string{filepath.Join(t.TempDir(), "missing.yaml")})
+	assert.Error(t, err)
+}
+
+func TestRunOfflineConverter(t *testing.T) {
+	dir := writeTestInputs(t)
+	out := t.TempDir()
+
+	conversionInfos = nil
+	skippedInputObjects = nil
+	inputFiles = []string{dir}
+	outputDir = out
+	logFilePath = filepath.Join(out, "log.txt")
+	reportPath = filepath.Join(out, "report.pdf")
+	reportFormats = []string{"pdf"}
+	defer func() { inputFiles = nil }()
+
+	assert.NoError(t, runOfflineConverter(&cobra.Command{}))
+
+	assert.FileExists(t, filepath.Join(out, "team-a", "web.yaml"))
+	assert.FileExists(t, filepath.Join(out, offlineDefaultNamespace, "api.yaml"))
+	assert.FileExists(t, reportPath)
+	assert.Len(t, conversionInfos, 2)
+	assert.ElementsMatch(t, []string{"Service web", "ConfigMap api"}, skippedInputObjects)
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/util/homedir"
@@ -18,34 +20,116 @@ var (
 	reservedNamespaces  []string
 	logFilePath         string
 	openShiftProjects   []string
+	allProjects         bool
+	projectSelector     string
+	dcSelector          string
+	includePatterns     []string
+	excludePatterns     []string
 	reportPath          string
+	reportFormats       []string
+	inputFiles          []string
+	cutover             bool
+	cutoverStep         int64
+	cutoverTimeout      time.Duration
+	cutoverFinalAction  string
+	forceConflicts      bool
+	validateMode        string
+	allowInvalid        bool
+	failOn              string
+	backupDir           string
+	concurrency         int
+	pageSize            int64
+	dcConcurrency       int
+	clientQPS           float32
+	clientBurst         int
+	backupFormat        string
+
+	rollbackRunID             string
+	rollbackSince             string
+	rollbackBackupPaths       []string
+	rollbackDryRun            bool
+	rollbackDeleteDeployments bool
+	rollbackTimeout           time.Duration
+	rollbackReportPath        string
+
+	scanSortBy     string
+	scanReportPath string
 )
 
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "openshift-dc-converter",
 		Short: "Convert OpenShift DeploymentConfigs to Kubernetes Deployments",
-		Long:  `A CLI tool to convert OpenShift DeploymentConfigs to Kubernetes Deployments across specified projects and generate a PDF report.`,
+		Long:  `A CLI tool to convert OpenShift DeploymentConfigs to Kubernetes Deployments across specified projects, or from local manifests, and generate a PDF report.`,
 		RunE:  runConverter,
 	}
 
-	rootCmd.Flags().StringVar(&kubeconfig, "kubeconfig", filepath.Join(homedir.HomeDir(), ".kube", "config"), "Path to the kubeconfig file")
+	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", filepath.Join(homedir.HomeDir(), ".kube", "config"), "Path to the kubeconfig file")
 	rootCmd.Flags().StringVar(&outputDir, "output-dir", "./converted_deployments", "Directory to store converted Deployment YAML files")
 	rootCmd.Flags().BoolVar(&applyChanges, "apply-changes", false, "Apply the converted Deployments to the cluster")
+	rootCmd.Flags().StringVar(&failOn, "fail-on", "none", "Exit with status 2 when a finding reaches this severity, or a DeploymentConfig fails: none, info, warning or error")
+	rootCmd.Flags().BoolVar(&allowInvalid, "allow-invalid", false, "Save and apply converted Deployments that fail schema validation")
+	rootCmd.Flags().BoolVar(&forceConflicts, "force-conflicts", false, "Take ownership of Deployment fields managed by other field managers when applying")
+	rootCmd.Flags().StringVar(&validateMode, "validate", "none", "Validate the converted Deployments: none, or server to submit them as a dry-run through admission")
 	rootCmd.Flags().BoolVar(&preserveAnnotations, "preserve-annotations", true, "Preserve existing annotations in the converted Deployments")
 	rootCmd.Flags().BoolVar(&preserveLabels, "preserve-labels", true, "Preserve existing labels in the converted Deployments")
-	rootCmd.Flags().StringSliceVar(&reservedNamespaces, "reserved-namespaces", []string{"default", "openshift", "openshift-infra"}, "List of reserved namespaces to skip")
-	rootCmd.Flags().StringVar(&logFilePath, "log-file", "conversion_log.txt", "Path to the log file")
-	rootCmd.Flags().StringSliceVar(&openShiftProjects, "projects", []string{}, "List of OpenShift projects to scan and convert")
-	rootCmd.Flags().StringVar(&reportPath, "report-path", "conversion_report.pdf", "Path to save the PDF report")
+	rootCmd.PersistentFlags().StringSliceVar(&reservedNamespaces, "reserved-namespaces", []string{"default", "openshift", "openshift-infra"}, "List of reserved namespaces to skip")
+	rootCmd.PersistentFlags().StringVar(&logFilePath, "log-file", "conversion_log.txt", "Path to the log file")
+	rootCmd.PersistentFlags().StringSliceVar(&openShiftProjects, "projects", []string{}, "List of OpenShift projects to scan and convert, glob patterns such as team-* are expanded")
+	rootCmd.PersistentFlags().BoolVar(&allProjects, "all-projects", false, "Scan and convert every non-reserved project")
+	rootCmd.PersistentFlags().StringVar(&projectSelector, "project-selector", "", "Label selector choosing the projects to scan and convert")
+	rootCmd.Flags().StringVar(&dcSelector, "selector", "", "Label selector choosing the DeploymentConfigs to convert")
+	rootCmd.Flags().StringSliceVar(&includePatterns, "include", []string{}, "Only convert DeploymentConfigs whose name matches one of these glob patterns")
+	rootCmd.Flags().StringSliceVar(&excludePatterns, "exclude", []string{}, "Skip DeploymentConfigs whose name matches one of these glob patterns")
+	rootCmd.Flags().StringVar(&reportPath, "report-path", "conversion_report.pdf", "Path to save the report; other formats replace its extension")
+	rootCmd.Flags().StringSliceVar(&reportFormats, "report-format", []string{"pdf"}, "Formats of the report: pdf, json, csv, junit and/or html")
+	rootCmd.Flags().BoolVar(&cutover, "cutover", false, "Create each Deployment, wait for it to become Available and scale the DeploymentConfig down (implies --apply-changes)")
+	rootCmd.Flags().Int64Var(&cutoverStep, "cutover-step", 0, "Number of DeploymentConfig replicas to remove per cutover step (0 scales down at once)")
+	rootCmd.Flags().DurationVar(&cutoverTimeout, "cutover-timeout", 10*time.Minute, "Maximum time to wait for the Deployment to become Available during each cutover step, and before switching dependent resources with --apply-changes")
+	rootCmd.Flags().StringVar(&cutoverFinalAction, "cutover-final-action", "pause", "What to do with the DeploymentConfig once scaled to zero: pause or delete")
+	rootCmd.Flags().IntVar(&concurrency, "concurrency", 1, "Number of projects to process in parallel")
+	rootCmd.PersistentFlags().Int64Var(&pageSize, "page-size", 500, "Number of DeploymentConfigs to list per request (0 lists a project at once)")
+	rootCmd.Flags().IntVar(&dcConcurrency, "dc-concurrency", 1, "Number of DeploymentConfigs to process in parallel within each project")
+	rootCmd.PersistentFlags().Float32Var(&clientQPS, "qps", 5, "Maximum queries per second to the API server")
+	rootCmd.PersistentFlags().IntVar(&clientBurst, "burst", 10, "Maximum burst of queries to the API server")
+	rootCmd.PersistentFlags().StringVar(&backupDir, "backup-dir", "./backups", "Directory holding the backups of the original objects taken before the cluster is changed")
+	rootCmd.Flags().StringVar(&backupFormat, "backup-format", "dir", "Format of the backup: dir or tar.gz")
+	rootCmd.Flags().StringArrayVar(&inputFiles, "from-file", []string{}, "Convert DeploymentConfigs read from a file, directory or '-' for stdin instead of the cluster (repeatable)")
 
-	if err := rootCmd.MarkFlagRequired("projects"); err != nil {
-		fmt.Println("Error marking 'projects' flag as required:", err)
-		os.Exit(1)
+	rollbackCmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Undo the Deployments created by a migration run",
+		Long:  `Find the Deployments generated by the migration, restore their DeploymentConfigs and dependent resources, optionally from backup manifests, and delete or scale down the Deployments. Runs in dry-run mode unless --dry-run=false is given.`,
+		RunE:  runRollback,
 	}
 
+	rollbackCmd.Flags().StringVar(&rollbackRunID, "run-id", "", "Only roll back Deployments created by this migration run")
+	rollbackCmd.Flags().StringVar(&rollbackSince, "since", "", "Only roll back Deployments migrated at or after this RFC3339 time")
+	rollbackCmd.Flags().StringArrayVar(&rollbackBackupPaths, "backup", []string{}, "Backup directory or archive, or DeploymentConfig and dependent resource manifests, to restore from: a file, directory or '-' for stdin (repeatable, defaults to the backup of --run-id in --backup-dir)")
+	rollbackCmd.Flags().BoolVar(&rollbackDryRun, "dry-run", true, "Only report what would be rolled back")
+	rollbackCmd.Flags().BoolVar(&rollbackDeleteDeployments, "delete-deployments", true, "Delete the generated Deployments instead of scaling them to zero")
+	rollbackCmd.Flags().DurationVar(&rollbackTimeout, "timeout", 10*time.Minute, "Maximum time to wait for a restored DeploymentConfig to become available")
+	rollbackCmd.Flags().StringVar(&rollbackReportPath, "report-path", "rollback_report.pdf", "Path to save the rollback PDF report")
+	rootCmd.AddCommand(rollbackCmd)
+
+	scanCmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Assess the DeploymentConfigs of the projects without converting them",
+		Long:  `List the DeploymentConfigs of the projects with a weighted migration complexity score and a readiness category (automatic, needs review or manual) each, and generate a PDF inventory. Nothing is converted or written to the cluster.`,
+		RunE:  runScan,
+	}
+
+	scanCmd.Flags().StringVar(&scanSortBy, "sort-by", "score", "Order of the inventory: score, readiness, namespace or name")
+	scanCmd.Flags().StringVar(&scanReportPath, "report-path", "scan_report.pdf", "Path to save the scan PDF report")
+	rootCmd.AddCommand(scanCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println("Error executing command:", err)
+		// Findings reaching --fail-on are told apart from failures for CI.
+		var findingsErr *FindingsError
+		if errors.As(err, &findingsErr) {
+			os.Exit(2)
+		}
 		os.Exit(1)
 	}
 }
@@ -2,38 +2,78 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestGeneratePDFReport(t *testing.T) {
-	// Set up test data
-	conversionInfos = []ConversionInfo{
-		{
-			Timestamp:            "2024-08-16T08:47:03-05:00",
-			Namespace:            "test-namespace",
-			DeploymentConfigName: "test-dc",
-			HasTriggers:          true,
-			HasLifecycleHooks:    false,
-			HasAutoRollbacks:     false,
-			UsesCustomStrategies: false,
+func newTestReport() *Report {
+	return &Report{
+		RunID:              "20240816-134703",
+		ProjectsInScope:    []string{"test-namespace"},
+		ProjectScopeSource: "--projects test-*",
+		Conversions: []ConversionInfo{
+			{
+				Timestamp:            "2024-08-16T08:47:03-05:00",
+				Namespace:            "test-namespace",
+				DeploymentConfigName: "test-dc",
+				Outcome:              OutcomeConverted,
+				Findings: []Finding{
+					newFinding(codeTriggers, "spec.triggers", "DeploymentConfig has ConfigChange or ImageChange triggers"),
+					newFinding(codeHook, "spec.strategy.rollingParams.pre.execNewPod", "rollingParams pre hook converted to Job test-dc-pre-hook (PreSync)"),
+					newFinding(codeUnmappedField, "spec.test", "spec.test is not carried over to the Deployment"),
+				},
+				LifecycleHooks: []HookSummary{
+					{Params: "rollingParams", Phase: "pre", Actions: []string{"execNewPod"}, FailurePolicy: "Abort"},
+				},
+			},
+			{
+				Timestamp:            "2024-08-16T08:47:04-05:00",
+				Namespace:            "test-namespace",
+				DeploymentConfigName: "custom-dc",
+				Outcome:              OutcomeFailed,
+				Error:                "error applying Deployment custom-dc: conflict",
+				ApplyOutcome:         ApplyConflicted,
+				Findings: []Finding{
+					newFinding(codeCustomStrategy, "spec.strategy.customParams", "DeploymentConfig uses a Custom deployment strategy"),
+				},
+			},
+			{
+				Namespace:            "other-namespace",
+				DeploymentConfigName: "skipped-dc",
+				Outcome:              OutcomeSkipped,
+				SkipReason:           "annotated with migration.openshift.io/skip=true",
+			},
 		},
 	}
+}
 
-	// Generate the report
-	reportPath := "test_report.pdf"
-	err := generatePDFReport(reportPath)
+func TestGeneratePDFReport(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "test_report.pdf")
+	paths, err := writeReports(newTestReport(), reportPath, []string{"pdf"})
 
-	// Assert no error occurred
 	assert.NoError(t, err)
-
-	// Check if the file was created
-	_, err = os.Stat(reportPath)
+	assert.Equal(t, []string{reportPath}, paths)
+	info, err := os.Stat(reportPath)
 	assert.NoError(t, err)
+	assert.NotZero(t, info.Size())
+}
+
+func TestSuccessfulConversions(t *testing.T) {
+	infos := []ConversionInfo{
+		{DeploymentConfigName: "converted", Outcome: OutcomeConverted},
+		{DeploymentConfigName: "applied", Outcome: OutcomeApplied},
+		{DeploymentConfigName: "skipped", Outcome: OutcomeSkipped},
+		{DeploymentConfigName: "failed", Outcome: OutcomeFailed},
+		{DeploymentConfigName: "panicked", Outcome: OutcomePanicked},
+	}
 
-	// Clean up the test file
-	os.Remove(reportPath)
+	var names []string
+	for _, info := range successfulConversions(infos) {
+		names = append(names, info.DeploymentConfigName)
+	}
+	assert.Equal(t, []string{"converted", "applied"}, names)
 }
 
 func TestBoolToString(t *testing.T) {
@@ -58,3 +98,27 @@ func TestParseAndFormatDate(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateRollbackReport(t *testing.T) {
+	rollbackDryRun = true
+	rollbackResults = []RollbackResult{
+		{Namespace: "test-namespace", Name: "test-dc", RunID: "20240816-134703", Actions: []string{"would delete Deployment"}},
+		{Namespace: "test-namespace", Name: "other-dc", Error: "DeploymentConfig other-dc no longer exists and no backup of it was provided"},
+	}
+	defer func() { rollbackResults = nil }()
+
+	reportPath := filepath.Join(t.TempDir(), "rollback_report.pdf")
+	assert.NoError(t, generateRollbackReport(reportPath))
+	assert.FileExists(t, reportPath)
+}
+
+func TestGenerateScanReport(t *testing.T) {
+	scanResults = []ScanResult{
+		{Namespace: "test-namespace", DeploymentConfigName: "test-dc", Score: 4, Readiness: ReadinessNeedsReview, Factors: []string{"triggers (+1)", "execNewPod hooks (+3)"}},
+	}
+	defer func() { scanResults = nil }()
+
+	reportPath := filepath.Join(t.TempDir(), "scan_report.pdf")
+	assert.NoError(t, generateScanReport(reportPath))
+	assert.FileExists(t, reportPath)
+}
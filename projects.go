@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	namespaceRes = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+	projectRes   = schema.GroupVersionResource{Group: "project.openshift.io", Version: "v1", Resource: "projects"}
+)
+
+// projectsInScope lists the projects the run was restricted to, and
+// projectScopeSource the flags they were chosen by, for the report.
+var (
+	projectsInScope    []string
+	projectScopeSource string
+)
+
+// isProjectPattern reports whether a --projects entry is a glob pattern
+// rather than a project name.
+func isProjectPattern(project string) bool {
+	return strings.ContainsAny(project, "*?[")
+}
+
+// resolveProjects expands the --projects names and glob patterns,
+// --all-projects and --project-selector into the sorted list of existing,
+// non-reserved projects to process. Names given explicitly are always
+// included; patterns, --all-projects and the selector are matched against the
+// projects listed from the cluster.
+func resolveProjects(client dynamic.Interface, projects []string, all bool, selector string) ([]string, error) {
+	var names, patterns []string
+	for _, project := range projects {
+		if isProjectPattern(project) {
+			if _, err := path.Match(project, ""); err != nil {
+				return nil, fmt.Errorf("invalid project pattern %q: %w", project, err)
+			}
+			patterns = append(patterns, project)
+		} else {
+			names = append(names, project)
+		}
+	}
+
+	resolved := map[string]bool{}
+	for _, project := range validateProjects(client, names) {
+		resolved[project] = true
+	}
+
+	if all || selector != "" || len(patterns) > 0 {
+		if _, err := labels.Parse(selector); err != nil {
+			return nil, fmt.Errorf("invalid --project-selector %q: %w", selector, err)
+		}
+		discovered, err := listProjects(client, selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, project := range discovered {
+			if !all && len(patterns) > 0 && !matchesAnyPattern(project, patterns) {
+				continue
+			}
+			if isReservedNamespace(project) {
+				logErr := logMessage(fmt.Sprintf("Skipping reserved namespace %s", project))
+				if logErr != nil {
+					fmt.Printf("Failed to log message: %v\n", logErr)
+				}
+				continue
+			}
+			resolved[project] = true
+		}
+	}
+
+	if len(resolved) == 0 {
+		return nil, fmt.Errorf("no valid projects found among the provided projects")
+	}
+	var scope []string
+	for project := range resolved {
+		scope = append(scope, project)
+	}
+	sort.Strings(scope)
+	return scope, nil
+}
+
+func matchesAnyPattern(project string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, project); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// listProjects lists the names of the projects matching the label selector.
+// The OpenShift projects API only returns the projects the user has access to;
+// namespaces are listed instead on clusters without it.
+func listProjects(client dynamic.Interface, selector string) ([]string, error) {
+	ctx := context.Background()
+	opts := metav1.ListOptions{LabelSelector: selector}
+	list, err := client.Resource(projectRes).List(ctx, opts)
+	if apierrors.IsNotFound(err) {
+		list, err = client.Resource(namespaceRes).List(ctx, opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing projects: %w", err)
+	}
+
+	var projects []string
+	for _, item := range list.Items {
+		projects = append(projects, item.GetName())
+	}
+	return projects, nil
+}
+
+// describeProjectScope summarizes the flags the projects of the run were
+// chosen by.
+func describeProjectScope(projects []string, all bool, selector string) string {
+	var parts []string
+	if all {
+		parts = append(parts, "--all-projects")
+	}
+	if selector != "" {
+		parts = append(parts, fmt.Sprintf("--project-selector %s", selector))
+	}
+	if len(projects) > 0 {
+		parts = append(parts, fmt.Sprintf("--projects %s", strings.Join(projects, ",")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// recordProjectScope prints and logs the projects in scope and keeps them for
+// the report.
+func recordProjectScope(scope []string) {
+	projectsInScope = scope
+	projectScopeSource = describeProjectScope(openShiftProjects, allProjects, projectSelector)
+
+	message := fmt.Sprintf("Projects in scope (%d, from %s): %s", len(scope), projectScopeSource, strings.Join(scope, ", "))
+	fmt.Println(message)
+	logErr := logMessage(message)
+	if logErr != nil {
+		fmt.Printf("Failed to log message: %v\n", logErr)
+	}
+}
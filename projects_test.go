@@ -0,0 +1,97 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newProject(apiVersion, kind, name string, labels map[string]string) *unstructured.Unstructured {
+	project := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+	}}
+	project.SetName(name)
+	project.SetLabels(labels)
+	return project
+}
+
+func newProjectsClient() *dynamicfake.FakeDynamicClient {
+	var objects []runtime.Object
+	for _, name := range []string{"team-a", "team-b", "payments", "openshift-monitoring", "kube-system"} {
+		labels := map[string]string{}
+		if name == "team-b" || name == "payments" {
+			labels["billing"] = "true"
+		}
+		objects = append(objects,
+			newProject("project.openshift.io/v1", "Project", name, labels),
+			newProject("v1", "Namespace", name, labels))
+	}
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		projectRes:   "ProjectList",
+		namespaceRes: "NamespaceList",
+	}, objects...)
+}
+
+func TestResolveProjects(t *testing.T) {
+	logFilePath = filepath.Join(t.TempDir(), "log.txt")
+
+	tests := []struct {
+		name     string
+		projects []string
+		all      bool
+		selector string
+		expected []string
+	}{
+		{"Explicit names", []string{"payments", "team-a", "missing"}, false, "", []string{"payments", "team-a"}},
+		{"Glob pattern", []string{"team-*"}, false, "", []string{"team-a", "team-b"}},
+		{"Glob pattern and name", []string{"team-?", "payments"}, false, "", []string{"payments", "team-a", "team-b"}},
+		{"All projects skip reserved namespaces", nil, true, "", []string{"payments", "team-a", "team-b"}},
+		{"Label selector", nil, false, "billing=true", []string{"payments", "team-b"}},
+		{"Label selector and glob pattern", []string{"team-*"}, false, "billing=true", []string{"team-b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects, err := resolveProjects(newProjectsClient(), tt.projects, tt.all, tt.selector)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, projects)
+		})
+	}
+}
+
+func TestResolveProjectsErrors(t *testing.T) {
+	logFilePath = filepath.Join(t.TempDir(), "log.txt")
+
+	_, err := resolveProjects(newProjectsClient(), []string{"openshift-*"}, false, "")
+	assert.ErrorContains(t, err, "no valid projects")
+
+	_, err = resolveProjects(newProjectsClient(), []string{"team-["}, false, "")
+	assert.ErrorContains(t, err, "invalid project pattern")
+
+	_, err = resolveProjects(newProjectsClient(), nil, false, "billing in (")
+	assert.ErrorContains(t, err, "invalid --project-selector")
+}
+
+func TestListProjectsFallsBackToNamespaces(t *testing.T) {
+	client := newProjectsClient()
+	client.PrependReactor("list", "projects", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(projectRes.GroupResource(), "")
+	})
+
+	projects, err := listProjects(client, "billing=true")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"team-b", "payments"}, projects)
+}
+
+func TestDescribeProjectScope(t *testing.T) {
+	assert.Equal(t, "--all-projects", describeProjectScope(nil, true, ""))
+	assert.Equal(t, "--project-selector team=a, --projects web-*,api", describeProjectScope([]string{"web-*", "api"}, false, "team=a"))
+}
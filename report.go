@@ -2,12 +2,19 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
-func generatePDFReport(reportPath string) error {
+// pdfReportWriter renders the Report as a PDF document.
+type pdfReportWriter struct{}
+
+func (pdfReportWriter) Extension() string { return ".pdf" }
+
+func (pdfReportWriter) Write(w io.Writer, report *Report) error {
 	pdf := gofpdf.New("L", "mm", "A4", "")
 	pdf.AddPage()
 
@@ -17,7 +24,7 @@ func generatePDFReport(reportPath string) error {
 	pdf.Ln(15)
 
 	// Define column widths
-	colWidths := []float64{25, 30, 50, 25, 35, 30, 35}
+	colWidths := []float64{25, 30, 44, 20, 22, 24, 24, 24, 28, 30}
 	pageWidth, _ := pdf.GetPageSize()
 	tableWidth := 0.0
 	for _, w := range colWidths {
@@ -31,7 +38,7 @@ func generatePDFReport(reportPath string) error {
 	// Table headers
 	pdf.SetFont("Arial", "B", 10)
 	pdf.SetFillColor(200, 200, 200)
-	headers := []string{"Date", "Namespace", "DeploymentConfig Name", "Triggers", "Lifecycle Hooks", "Auto Rollbacks", "Custom Strategies"}
+	headers := []string{"Date", "Namespace", "DeploymentConfig Name", "Status", "Triggers", "Pre Hook", "Mid Hook", "Post Hook", "Auto Rollbacks", "Custom Strategies"}
 	for i, header := range headers {
 		pdf.CellFormat(colWidths[i], 7, header, "1", 0, "C", true, 0, "")
 	}
@@ -40,9 +47,13 @@ func generatePDFReport(reportPath string) error {
 	// Table content
 	pdf.SetFont("Arial", "", 9)
 	pdf.SetFillColor(255, 255, 255)
-	for i, info := range conversionInfos {
+	rows := 0
+	for _, info := range report.Conversions {
+		if info.SkipReason != "" {
+			continue
+		}
 		fillColor := false
-		if i%2 == 0 {
+		if rows%2 == 0 {
 			fillColor = true
 			pdf.SetFillColor(240, 240, 240)
 		} else {
@@ -52,22 +63,535 @@ func generatePDFReport(reportPath string) error {
 		date := parseAndFormatDate(info.Timestamp)
 		pdf.CellFormat(colWidths[0], 6, date, "1", 0, "C", fillColor, 0, "")
 		pdf.CellFormat(colWidths[1], 6, info.Namespace, "1", 0, "L", fillColor, 0, "")
-		pdf.CellFormat(colWidths[2], 6, info.DeploymentConfigName, "1", 0, "L", fillColor, 0, "")
-		pdf.CellFormat(colWidths[3], 6, boolToString(info.HasTriggers), "1", 0, "C", fillColor, 0, "")
-		pdf.CellFormat(colWidths[4], 6, boolToString(info.HasLifecycleHooks), "1", 0, "C", fillColor, 0, "")
-		pdf.CellFormat(colWidths[5], 6, boolToString(info.HasAutoRollbacks), "1", 0, "C", fillColor, 0, "")
-		pdf.CellFormat(colWidths[6], 6, boolToString(info.UsesCustomStrategies), "1", 0, "C", fillColor, 0, "")
+		pdf.CellFormat(colWidths[2], 6, displayName(info), "1", 0, "L", fillColor, 0, "")
+		pdf.CellFormat(colWidths[3], 6, string(info.Outcome), "1", 0, "C", fillColor, 0, "")
+		pdf.CellFormat(colWidths[4], 6, boolToString(info.hasFinding(codeTriggers)), "1", 0, "C", fillColor, 0, "")
+		pdf.CellFormat(colWidths[5], 6, hookActions(info.LifecycleHooks, "pre"), "1", 0, "C", fillColor, 0, "")
+		pdf.CellFormat(colWidths[6], 6, hookActions(info.LifecycleHooks, "mid"), "1", 0, "C", fillColor, 0, "")
+		pdf.CellFormat(colWidths[7], 6, hookActions(info.LifecycleHooks, "post"), "1", 0, "C", fillColor, 0, "")
+		pdf.CellFormat(colWidths[8], 6, boolToString(info.hasFinding(codeAutoRollback)), "1", 0, "C", fillColor, 0, "")
+		pdf.CellFormat(colWidths[9], 6, boolToString(info.hasFinding(codeCustomStrategy)), "1", 0, "C", fillColor, 0, "")
 		pdf.Ln(-1)
+		rows++
 	}
 
 	// Add summary
+	totals := totalsByNamespace(report.Conversions)
+	sum := sumTotals(totals)
 	pdf.Ln(10)
 	pdf.SetFont("Arial", "B", 12)
-	pdf.CellFormat(0, 10, fmt.Sprintf("Total Conversions: %d", len(conversionInfos)), "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 10, fmt.Sprintf("Total DeploymentConfigs: %d (%d succeeded, %d failed, %d skipped)", len(report.Conversions), sum.Succeeded, sum.Failed, sum.Skipped), "", 0, "L", false, 0, "")
+	pdf.Ln(10)
+	pdf.SetFont("Arial", "", 9)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Migration run ID: %s", report.RunID), "", 1, "L", false, 0, "")
+	if report.BackupLocation != "" {
+		pdf.CellFormat(0, 6, fmt.Sprintf("Backup of the original objects: %s", report.BackupLocation), "", 1, "L", false, 0, "")
+	}
+	writeProjectScope(pdf, report.ProjectsInScope, report.ProjectScopeSource)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Findings: %d errors, %d warnings, %d info", countSeverity(report.Conversions, SeverityError), countSeverity(report.Conversions, SeverityWarning), countSeverity(report.Conversions, SeverityInfo)), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	writeNamespaceTotals(pdf, totals)
+	writeFailedDeploymentConfigs(pdf, report)
+	writeValidationErrors(pdf, report)
+	writeUnmappedFields(pdf, report)
+	writeRolloutNotes(pdf, report)
+	writeSelectorNotes(pdf, report)
+	writeImageResolutions(pdf, report)
+	writeHookNotes(pdf, report)
+	writeDependentRewrites(pdf, report)
+	writeCutoverSteps(pdf, report)
+	writeApplyOutcomes(pdf, report)
+	writeServerValidation(pdf, report)
+	writeManualFollowUps(pdf, report)
+	writeSkippedDeploymentConfigs(pdf, report)
+	writeSkippedInputObjects(pdf, report)
+
+	return pdf.Output(w)
+}
+
+// writeProjectScope lists the projects the run was restricted to, so that
+// auditors can see what was in scope.
+func writeProjectScope(pdf *gofpdf.Fpdf, projects []string, source string) {
+	if len(projects) == 0 {
+		return
+	}
+	pdf.SetFont("Arial", "", 9)
+	pdf.MultiCell(0, 5, fmt.Sprintf("Projects in scope (%d, from %s): %s", len(projects), source, strings.Join(projects, ", ")), "", "L", false)
+}
+
+// writeNamespaceTotals writes a table of the succeeded, failed and skipped
+// DeploymentConfigs of each namespace.
+func writeNamespaceTotals(pdf *gofpdf.Fpdf, totals []namespaceTotals) {
+	if len(totals) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Outcomes per Namespace", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "B", 9)
+	pdf.SetFillColor(200, 200, 200)
+	colWidths := []float64{60, 30, 30, 30}
+	for i, header := range []string{"Namespace", "Succeeded", "Failed", "Skipped"} {
+		pdf.CellFormat(colWidths[i], 6, header, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Arial", "", 9)
+	for _, t := range totals {
+		pdf.CellFormat(colWidths[0], 6, t.Namespace, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths[1], 6, fmt.Sprint(t.Succeeded), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[2], 6, fmt.Sprint(t.Failed), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(colWidths[3], 6, fmt.Sprint(t.Skipped), "1", 0, "C", false, 0, "")
+		pdf.Ln(-1)
+	}
+	pdf.Ln(5)
+}
+
+// writeFailedDeploymentConfigs lists the DeploymentConfigs that failed or
+// panicked and the error that stopped them.
+func writeFailedDeploymentConfigs(pdf *gofpdf.Fpdf, report *Report) {
+	var failed []ConversionInfo
+	for _, info := range report.Conversions {
+		if info.Outcome.failed() {
+			failed = append(failed, info)
+		}
+	}
+	if len(failed) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, fmt.Sprintf("Failed DeploymentConfigs: %d", len(failed)), "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	for _, info := range failed {
+		pdf.MultiCell(0, 5, fmt.Sprintf("- %s/%s (%s): %s", info.Namespace, displayName(info), info.Outcome, info.Error), "", "L", false)
+	}
+	pdf.Ln(5)
+}
+
+// countSeverity counts the findings of exactly the severity.
+func countSeverity(infos []ConversionInfo, severity Severity) int {
+	count := 0
+	for _, info := range infos {
+		for _, finding := range info.Findings {
+			if finding.Severity == severity {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// infosWithFindings returns the ConversionInfos with a finding of one of the
+// codes.
+func infosWithFindings(infos []ConversionInfo, codes ...string) []ConversionInfo {
+	var found []ConversionInfo
+	for _, info := range infos {
+		if len(info.findingsOf(codes...)) > 0 {
+			found = append(found, info)
+		}
+	}
+	return found
+}
+
+// writeFindings lists the findings with one of the codes of each
+// DeploymentConfig, followed by the remediation of every code found.
+func writeFindings(pdf *gofpdf.Fpdf, infos []ConversionInfo, codes ...string) {
+	var remediations []string
+	seen := map[string]bool{}
+	for _, info := range infos {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(0, 6, fmt.Sprintf("%s/%s", info.Namespace, displayName(info)), "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 9)
+		for _, finding := range info.findingsOf(codes...) {
+			pdf.MultiCell(0, 5, "- "+formatFinding(finding), "", "L", false)
+			if !seen[finding.Code] {
+				seen[finding.Code] = true
+				remediations = append(remediations, finding.Remediation)
+			}
+		}
+	}
+	if len(remediations) == 0 {
+		return
+	}
+	pdf.SetFont("Arial", "I", 9)
+	pdf.CellFormat(0, 6, "What to do:", "", 1, "L", false, 0, "")
+	for _, remediation := range remediations {
+		pdf.MultiCell(0, 5, "- "+remediation, "", "L", false)
+	}
+}
+
+func writeValidationErrors(pdf *gofpdf.Fpdf, report *Report) {
+	invalid := infosWithFindings(report.Conversions, codeInvalidDeployment)
+	if len(invalid) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Validation Failures", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	status := "were not saved or applied"
+	if report.AllowInvalid {
+		status = "were saved because --allow-invalid was set"
+	}
+	pdf.CellFormat(0, 6, fmt.Sprintf("%d converted Deployments failed validation and %s.", len(invalid), status), "", 1, "L", false, 0, "")
+	writeFindings(pdf, invalid, codeInvalidDeployment)
+	pdf.Ln(5)
+}
+
+// successfulConversions returns the conversions that produced a Deployment.
+func successfulConversions(infos []ConversionInfo) []ConversionInfo {
+	var converted []ConversionInfo
+	for _, info := range infos {
+		if info.Outcome.succeeded() {
+			converted = append(converted, info)
+		}
+	}
+	return converted
+}
+
+func writeUnmappedFields(pdf *gofpdf.Fpdf, report *Report) {
+	// Skipped and failed DeploymentConfigs produced no Deployment to lose
+	// fields in.
+	converted := successfulConversions(report.Conversions)
+	unmapped := infosWithFindings(converted, codeUnmappedField)
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Unmapped Fields", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	pdf.CellFormat(0, 6, fmt.Sprintf("%d of %d DeploymentConfigs were converted without losing any spec field.", len(converted)-len(unmapped), len(converted)), "", 1, "L", false, 0, "")
+	writeFindings(pdf, unmapped, codeUnmappedField)
+	pdf.Ln(5)
+}
+
+func writeRolloutNotes(pdf *gofpdf.Fpdf, report *Report) {
+	if len(report.Conversions) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Rollout Timing", "", 1, "L", false, 0, "")
+	writeFindings(pdf, infosWithFindings(report.Conversions, codeRolloutTiming), codeRolloutTiming)
+	pdf.Ln(5)
+}
+
+func writeSelectorNotes(pdf *gofpdf.Fpdf, report *Report) {
+	repaired := infosWithFindings(report.Conversions, codeSelectorRepair, codeSelectorOverlap)
+	if len(repaired) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Selectors", "", 1, "L", false, 0, "")
+	writeFindings(pdf, repaired, codeSelectorRepair, codeSelectorOverlap)
+	pdf.Ln(5)
+}
+
+func writeImageResolutions(pdf *gofpdf.Fpdf, report *Report) {
+	var resolved []ConversionInfo
+	for _, info := range report.Conversions {
+		if len(info.ImageResolutions) > 0 {
+			resolved = append(resolved, info)
+		}
+	}
+	if len(resolved) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Image Resolutions", "", 1, "L", false, 0, "")
+	for _, info := range resolved {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(0, 6, fmt.Sprintf("%s/%s", info.Namespace, displayName(info)), "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 8)
+		for _, resolution := range info.ImageResolutions {
+			pdf.MultiCell(0, 4, fmt.Sprintf("- %s: %q -> %q (%s)", resolution.Container, resolution.Original, resolution.Resolved, resolution.Source), "", "L", false)
+		}
+	}
+	pdf.Ln(5)
+}
+
+func writeHookNotes(pdf *gofpdf.Fpdf, report *Report) {
+	hooked := infosWithFindings(report.Conversions, codeHook)
+	if len(hooked) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Lifecycle Hooks", "", 1, "L", false, 0, "")
+	writeFindings(pdf, hooked, codeHook)
+	pdf.Ln(5)
+}
+
+func writeDependentRewrites(pdf *gofpdf.Fpdf, report *Report) {
+	var rewritten []ConversionInfo
+	for _, info := range report.Conversions {
+		if len(info.DependentRewrites) > 0 {
+			rewritten = append(rewritten, info)
+		}
+	}
+	if len(rewritten) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Dependent Resources", "", 1, "L", false, 0, "")
+	for _, info := range rewritten {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(0, 6, fmt.Sprintf("%s/%s", info.Namespace, displayName(info)), "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 9)
+		for _, rewrite := range info.DependentRewrites {
+			status := "written"
+			if rewrite.Applied {
+				status = "applied"
+			}
+			for _, change := range rewrite.Changes {
+				pdf.MultiCell(0, 5, fmt.Sprintf("- %s %s (%s): %s", rewrite.Kind, rewrite.Name, status, change), "", "L", false)
+			}
+		}
+	}
+	pdf.Ln(5)
+}
+
+func writeCutoverSteps(pdf *gofpdf.Fpdf, report *Report) {
+	var cutOver []ConversionInfo
+	for _, info := range report.Conversions {
+		if len(info.CutoverSteps) > 0 {
+			cutOver = append(cutOver, info)
+		}
+	}
+	if len(cutOver) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Cutover", "", 1, "L", false, 0, "")
+	for _, info := range cutOver {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(0, 6, fmt.Sprintf("%s/%s", info.Namespace, displayName(info)), "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 9)
+		for _, step := range info.CutoverSteps {
+			line := fmt.Sprintf("- %s: %s", step.Name, step.Duration.Round(time.Millisecond))
+			if step.Error != "" {
+				line += " - failed: " + step.Error
+			}
+			pdf.MultiCell(0, 5, line, "", "L", false)
+		}
+	}
+	pdf.Ln(5)
+}
+
+func writeApplyOutcomes(pdf *gofpdf.Fpdf, report *Report) {
+	counts := map[ApplyOutcome]int{}
+	var applied []ConversionInfo
+	for _, info := range report.Conversions {
+		if info.ApplyOutcome != "" {
+			counts[info.ApplyOutcome]++
+			applied = append(applied, info)
+		}
+	}
+	if len(applied) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Server-Side Apply", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Field manager %s: %d created, %d updated, %d unchanged, %d conflicted.", fieldManager, counts[ApplyCreated], counts[ApplyUpdated], counts[ApplyUnchanged], counts[ApplyConflicted]), "", 1, "L", false, 0, "")
+	for _, info := range applied {
+		pdf.MultiCell(0, 5, fmt.Sprintf("- %s/%s: %s", info.Namespace, displayName(info), info.ApplyOutcome), "", "L", false)
+	}
+	pdf.Ln(5)
+}
+
+func writeServerValidation(pdf *gofpdf.Fpdf, report *Report) {
+	var validated, flagged []ConversionInfo
+	rejected := 0
+	for _, info := range report.Conversions {
+		if !info.Validated {
+			continue
+		}
+		validated = append(validated, info)
+		if info.hasFinding(codeAdmissionError) {
+			rejected++
+		}
+		if len(info.findingsOf(codeAdmissionError, codeAdmissionWarning)) > 0 {
+			flagged = append(flagged, info)
+		}
+	}
+	if len(validated) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Server-Side Validation", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	pdf.CellFormat(0, 6, fmt.Sprintf("%d of %d Deployments would be admitted by the cluster.", len(validated)-rejected, len(validated)), "", 1, "L", false, 0, "")
+	writeFindings(pdf, flagged, codeAdmissionError, codeAdmissionWarning)
+	pdf.Ln(5)
+}
+
+// followUpCodes are the findings listed as manual follow-ups.
+var followUpCodes = []string{codeManualImageTrigger, codeUnresolvedImage, codeUnpinnedImage, codeAutoRollback, codeCustomStrategy, codeApplyConflict}
+
+func writeManualFollowUps(pdf *gofpdf.Fpdf, report *Report) {
+	pending := infosWithFindings(report.Conversions, followUpCodes...)
+	if len(pending) == 0 {
+		return
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Manual Follow-ups", "", 1, "L", false, 0, "")
+	writeFindings(pdf, pending, followUpCodes...)
+}
+
+func writeSkippedDeploymentConfigs(pdf *gofpdf.Fpdf, report *Report) {
+	var skipped []ConversionInfo
+	for _, info := range report.Conversions {
+		if info.SkipReason != "" {
+			skipped = append(skipped, info)
+		}
+	}
+	if len(skipped) == 0 {
+		return
+	}
+
+	pdf.Ln(5)
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, fmt.Sprintf("Skipped DeploymentConfigs: %d", len(skipped)), "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	for _, info := range skipped {
+		pdf.MultiCell(0, 5, fmt.Sprintf("- %s/%s: %s", info.Namespace, info.DeploymentConfigName, info.SkipReason), "", "L", false)
+	}
+}
+
+func writeSkippedInputObjects(pdf *gofpdf.Fpdf, report *Report) {
+	if len(report.SkippedInputObjects) == 0 {
+		return
+	}
+
+	pdf.Ln(5)
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Skipped Input Objects", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	for _, object := range report.SkippedInputObjects {
+		pdf.MultiCell(0, 5, "- "+object, "", "L", false)
+	}
+}
+
+func generateRollbackReport(reportPath string) error {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 16)
+	pdf.Cell(0, 10, "DeploymentConfig Migration Rollback Report")
+	pdf.Ln(15)
+
+	writeProjectScope(pdf, projectsInScope, projectScopeSource)
+	writeRollbackResults(pdf)
 
 	return pdf.OutputFileAndClose(reportPath)
 }
 
+func writeRollbackResults(pdf *gofpdf.Fpdf) {
+	mode := "Applied"
+	if rollbackDryRun {
+		mode = "Dry run, nothing was changed"
+	}
+	failed := 0
+	for _, result := range rollbackResults {
+		if result.Error != "" {
+			failed++
+		}
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Rollback", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	pdf.CellFormat(0, 6, fmt.Sprintf("%s. %d Deployments rolled back, %d failed.", mode, len(rollbackResults)-failed, failed), "", 1, "L", false, 0, "")
+	if len(rollbackBackupSources) > 0 {
+		pdf.CellFormat(0, 6, fmt.Sprintf("Restored from: %s", strings.Join(rollbackBackupSources, ", ")), "", 1, "L", false, 0, "")
+	}
+	for _, result := range rollbackResults {
+		pdf.SetFont("Arial", "B", 9)
+		title := fmt.Sprintf("%s/%s", result.Namespace, result.Name)
+		if result.RunID != "" {
+			title += fmt.Sprintf(" (run %s)", result.RunID)
+		}
+		pdf.CellFormat(0, 6, title, "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 9)
+		for _, action := range result.Actions {
+			pdf.MultiCell(0, 5, "- "+action, "", "L", false)
+		}
+		if result.Error != "" {
+			pdf.MultiCell(0, 5, "- failed: "+result.Error, "", "L", false)
+		}
+	}
+	pdf.Ln(5)
+}
+
+func generateScanReport(reportPath string) error {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 16)
+	pdf.Cell(0, 10, "DeploymentConfig Migration Assessment")
+	pdf.Ln(15)
+
+	writeProjectScope(pdf, projectsInScope, projectScopeSource)
+	writeScanResults(pdf)
+
+	return pdf.OutputFileAndClose(reportPath)
+}
+
+func writeScanResults(pdf *gofpdf.Fpdf) {
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, fmt.Sprintf("Total: %s", scanSummary(scanResults)), "", 1, "L", false, 0, "")
+
+	colWidths := []float64{40, 55, 15, 25, 130}
+	pdf.SetFont("Arial", "B", 10)
+	pdf.SetFillColor(200, 200, 200)
+	for i, header := range []string{"Namespace", "DeploymentConfig", "Score", "Readiness", "Factors"} {
+		pdf.CellFormat(colWidths[i], 7, header, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "", 8)
+	for i, result := range scanResults {
+		fillColor := i%2 == 0
+		if fillColor {
+			pdf.SetFillColor(240, 240, 240)
+		}
+		factors := strings.Join(result.Factors, ", ")
+		if factors == "" {
+			factors = "-"
+		}
+		pdf.CellFormat(colWidths[0], 6, result.Namespace, "1", 0, "L", fillColor, 0, "")
+		pdf.CellFormat(colWidths[1], 6, result.DeploymentConfigName, "1", 0, "L", fillColor, 0, "")
+		pdf.CellFormat(colWidths[2], 6, fmt.Sprintf("%d", result.Score), "1", 0, "C", fillColor, 0, "")
+		pdf.CellFormat(colWidths[3], 6, string(result.Readiness), "1", 0, "C", fillColor, 0, "")
+		pdf.CellFormat(colWidths[4], 6, factors, "1", 0, "L", fillColor, 0, "")
+		pdf.Ln(-1)
+	}
+
+	pdf.Ln(5)
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(0, 7, "Complexity factors", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	for _, factor := range complexityFactors {
+		pdf.MultiCell(0, 5, fmt.Sprintf("- %s: weight %d, at least %s", factor.Name, factor.Weight, factor.Readiness), "", "L", false)
+	}
+}
+
+// displayName returns the DeploymentConfig name, qualified with its Template
+// when it was converted from one.
+func displayName(info ConversionInfo) string {
+	if info.Template != "" {
+		return fmt.Sprintf("%s (template %s)", info.DeploymentConfigName, info.Template)
+	}
+	return info.DeploymentConfigName
+}
+
 func boolToString(b bool) string {
 	if b {
 		return "Yes"
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Report is the outcome of a conversion run, as rendered by every
+// ReportWriter.
+type Report struct {
+	RunID               string           `json:"runID"`
+	BackupLocation      string           `json:"backupLocation,omitempty"`
+	ProjectsInScope     []string         `json:"projectsInScope,omitempty"`
+	ProjectScopeSource  string           `json:"projectScopeSource,omitempty"`
+	AllowInvalid        bool             `json:"allowInvalid"`
+	Conversions         []ConversionInfo `json:"conversions"`
+	SkippedInputObjects []string         `json:"skippedInputObjects,omitempty"`
+}
+
+// namespaceTotals counts the DeploymentConfigs of a namespace by outcome.
+// Panicked DeploymentConfigs count as failed.
+type namespaceTotals struct {
+	Namespace string
+	Succeeded int
+	Failed    int
+	Skipped   int
+}
+
+// totalsByNamespace returns the outcome totals of each namespace, in the order
+// the namespaces first appear.
+func totalsByNamespace(infos []ConversionInfo) []namespaceTotals {
+	var totals []namespaceTotals
+	index := map[string]int{}
+	for _, info := range infos {
+		i, ok := index[info.Namespace]
+		if !ok {
+			i = len(totals)
+			index[info.Namespace] = i
+			totals = append(totals, namespaceTotals{Namespace: info.Namespace})
+		}
+		switch {
+		case info.Outcome.succeeded():
+			totals[i].Succeeded++
+		case info.Outcome.failed():
+			totals[i].Failed++
+		case info.Outcome == OutcomeSkipped:
+			totals[i].Skipped++
+		}
+	}
+	return totals
+}
+
+// sumTotals adds up the totals of every namespace.
+func sumTotals(totals []namespaceTotals) namespaceTotals {
+	var sum namespaceTotals
+	for _, t := range totals {
+		sum.Succeeded += t.Succeeded
+		sum.Failed += t.Failed
+		sum.Skipped += t.Skipped
+	}
+	return sum
+}
+
+// ReportWriter renders a Report in one format.
+type ReportWriter interface {
+	// Extension is the file extension of the format, including the dot.
+	Extension() string
+	Write(w io.Writer, report *Report) error
+}
+
+// reportWriters maps the --report-format values to their writers.
+var reportWriters = map[string]ReportWriter{
+	"pdf":   pdfReportWriter{},
+	"json":  jsonReportWriter{},
+	"csv":   csvReportWriter{},
+	"junit": junitReportWriter{},
+	"html":  htmlReportWriter{},
+}
+
+// checkReportFormats validates the --report-format values.
+func checkReportFormats(formats []string) error {
+	if len(formats) == 0 {
+		return fmt.Errorf("at least one --report-format must be given")
+	}
+	for _, format := range formats {
+		if _, ok := reportWriters[format]; !ok {
+			return fmt.Errorf("invalid --report-format %q: must be pdf, json, csv, junit or html", format)
+		}
+	}
+	return nil
+}
+
+// newReport takes a snapshot of the results of the run.
+func newReport() *Report {
+	sortConversionInfos()
+	conversionInfosMu.Lock()
+	defer conversionInfosMu.Unlock()
+	return &Report{
+		RunID:               migrationRunID,
+		BackupLocation:      backupLocation,
+		ProjectsInScope:     projectsInScope,
+		ProjectScopeSource:  projectScopeSource,
+		AllowInvalid:        allowInvalid,
+		Conversions:         append([]ConversionInfo(nil), conversionInfos...),
+		SkippedInputObjects: skippedInputObjects,
+	}
+}
+
+// reportPathFor returns the path of the report in a format: the --report-path
+// with the extension of the format.
+func reportPathFor(basePath string, writer ReportWriter) string {
+	return strings.TrimSuffix(basePath, filepath.Ext(basePath)) + writer.Extension()
+}
+
+// writeReports writes the report in each format and returns the paths
+// written.
+func writeReports(report *Report, basePath string, formats []string) ([]string, error) {
+	var paths []string
+	for _, format := range formats {
+		writer := reportWriters[format]
+		path := reportPathFor(basePath, writer)
+		if err := writeReportFile(path, writer, report); err != nil {
+			return paths, fmt.Errorf("error writing %s report: %w", format, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func writeReportFile(path string, writer ReportWriter, report *Report) error {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := writer.Write(f, report); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+type jsonReportWriter struct{}
+
+func (jsonReportWriter) Extension() string { return ".json" }
+
+func (jsonReportWriter) Write(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// csvReportWriter writes a row per finding, and a single row without finding
+// columns for the DeploymentConfigs without findings.
+type csvReportWriter struct{}
+
+func (csvReportWriter) Extension() string { return ".csv" }
+
+func (csvReportWriter) Write(w io.Writer, report *Report) error {
+	out := csv.NewWriter(w)
+	header := []string{"namespace", "template", "deploymentconfig", "outcome", "error", "apply_outcome", "skip_reason", "code", "severity", "field", "message", "remediation"}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, info := range report.Conversions {
+		row := []string{info.Namespace, info.Template, info.DeploymentConfigName, string(info.Outcome), info.Error, string(info.ApplyOutcome), info.SkipReason}
+		if len(info.Findings) == 0 {
+			if err := out.Write(append(row, "", "", "", "", "")); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, finding := range info.Findings {
+			record := append(append([]string{}, row...), finding.Code, string(finding.Severity), finding.Field, finding.Message, finding.Remediation)
+			if err := out.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitReportWriter writes a test suite per namespace with a test case per
+// DeploymentConfig, which fails when the DeploymentConfig failed, panicked or
+// has an error finding. Warnings and info findings go to the test case output.
+type junitReportWriter struct{}
+
+func (junitReportWriter) Extension() string { return ".xml" }
+
+func (junitReportWriter) Write(w io.Writer, report *Report) error {
+	suites := junitTestSuites{Name: fmt.Sprintf("DeploymentConfig migration %s", report.RunID)}
+	index := map[string]int{}
+	for _, info := range report.Conversions {
+		i, ok := index[info.Namespace]
+		if !ok {
+			i = len(suites.Suites)
+			index[info.Namespace] = i
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: info.Namespace})
+		}
+		suite := &suites.Suites[i]
+
+		testCase := junitTestCase{Name: displayName(info), ClassName: info.Namespace}
+		var errs, others []string
+		for _, finding := range info.Findings {
+			line := fmt.Sprintf("%s %s", finding.Code, formatFinding(finding))
+			if finding.Severity == SeverityError {
+				errs = append(errs, line+"\n  "+finding.Remediation)
+			} else {
+				others = append(others, line)
+			}
+		}
+		switch {
+		case info.SkipReason != "":
+			testCase.Skipped = &junitMessage{Message: info.SkipReason}
+			suite.Skipped++
+			suites.Skipped++
+		case info.Error != "":
+			testCase.Failure = &junitMessage{Message: fmt.Sprintf("%s: %s", info.Outcome, info.Error), Text: strings.Join(errs, "\n")}
+			suite.Failures++
+			suites.Failures++
+		case len(errs) > 0:
+			testCase.Failure = &junitMessage{Message: fmt.Sprintf("%d error findings", len(errs)), Text: strings.Join(errs, "\n")}
+			suite.Failures++
+			suites.Failures++
+		}
+		testCase.SystemOut = strings.Join(others, "\n")
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+		suites.Tests++
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
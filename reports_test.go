@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckReportFormats(t *testing.T) {
+	assert.NoError(t, checkReportFormats([]string{"pdf", "json", "csv", "junit", "html"}))
+	assert.ErrorContains(t, checkReportFormats([]string{"pdf", "xlsx"}), `invalid --report-format "xlsx"`)
+	assert.Error(t, checkReportFormats(nil))
+}
+
+func TestWriteReportsMultipleFormats(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "conversion_report.pdf")
+	paths, err := writeReports(newTestReport(), base, []string{"pdf", "json", "csv", "junit"})
+
+	assert.NoError(t, err)
+	dir := filepath.Dir(base)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "conversion_report.pdf"),
+		filepath.Join(dir, "conversion_report.json"),
+		filepath.Join(dir, "conversion_report.csv"),
+		filepath.Join(dir, "conversion_report.xml"),
+	}, paths)
+	for _, path := range paths {
+		assert.FileExists(t, path)
+	}
+}
+
+func TestJSONReportWriter(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, jsonReportWriter{}.Write(&out, newTestReport()))
+
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, "20240816-134703", decoded.RunID)
+	assert.Len(t, decoded.Conversions, 3)
+	assert.Equal(t, SeverityError, decoded.Conversions[1].Findings[0].Severity)
+	assert.Contains(t, out.String(), `"deploymentConfig": "test-dc"`)
+}
+
+func TestCSVReportWriter(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, csvReportWriter{}.Write(&out, newTestReport()))
+
+	records, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	// A header, a row per finding of test-dc and custom-dc, one for skipped-dc.
+	assert.Len(t, records, 1+3+1+1)
+	assert.Equal(t, "namespace", records[0][0])
+	assert.Equal(t, []string{"test-namespace", "", "custom-dc", "failed", "error applying Deployment custom-dc: conflict", "conflicted", "", codeCustomStrategy, "error"}, records[4][:9])
+	assert.Equal(t, []string{"skipped", "", "", "annotated with migration.openshift.io/skip=true"}, records[5][3:7])
+	assert.Empty(t, records[5][7])
+}
+
+func TestTotalsByNamespace(t *testing.T) {
+	infos := append(newTestReport().Conversions, ConversionInfo{Namespace: "other-namespace", Outcome: OutcomePanicked})
+	totals := totalsByNamespace(infos)
+
+	assert.Equal(t, []namespaceTotals{
+		{Namespace: "test-namespace", Succeeded: 1, Failed: 1},
+		{Namespace: "other-namespace", Failed: 1, Skipped: 1},
+	}, totals)
+	assert.Equal(t, namespaceTotals{Succeeded: 1, Failed: 2, Skipped: 1}, sumTotals(totals))
+}
+
+func TestJUnitReportWriter(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, junitReportWriter{}.Write(&out, newTestReport()))
+
+	var suites junitTestSuites
+	assert.NoError(t, xml.Unmarshal(out.Bytes(), &suites))
+	assert.Equal(t, 3, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, 1, suites.Skipped)
+	assert.Len(t, suites.Suites, 2)
+
+	cases := suites.Suites[0].Cases
+	assert.Equal(t, "test-dc", cases[0].Name)
+	assert.Nil(t, cases[0].Failure)
+	assert.Contains(t, cases[0].SystemOut, "unmapped-field [warning]")
+	assert.NotNil(t, cases[1].Failure)
+	assert.Equal(t, "failed: error applying Deployment custom-dc: conflict", cases[1].Failure.Message)
+	assert.Contains(t, cases[1].Failure.Text, "custom-strategy [error]")
+	assert.NotNil(t, suites.Suites[1].Cases[0].Skipped)
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// RollbackResult records what the rollback did, or would do in dry-run mode,
+// for one generated Deployment.
+type RollbackResult struct {
+	Namespace string
+	Name      string
+	RunID     string
+	Actions   []string
+	Error     string
+}
+
+var rollbackResults []RollbackResult
+
+// rollbackBackupSources lists the backups the rollback restored objects from.
+var rollbackBackupSources []string
+
+// rollbackBackup indexes the objects read from the --backup manifests by
+// namespace, kind and name.
+type rollbackBackup struct {
+	objects  map[string]*unstructured.Unstructured
+	restored map[string]bool
+}
+
+func backupKey(namespace, kind, name string) string {
+	return namespace + "/" + kind + "/" + name
+}
+
+// loadRollbackBackup reads the objects of the given backups. Without paths,
+// the backup taken by the run being rolled back is used if it can be found in
+// the backup directory.
+func loadRollbackBackup(paths []string, runID string) (*rollbackBackup, error) {
+	backup := &rollbackBackup{
+		objects:  map[string]*unstructured.Unstructured{},
+		restored: map[string]bool{},
+	}
+	if len(paths) == 0 && runID != "" {
+		for _, candidate := range []string{filepath.Join(backupDir, runID), filepath.Join(backupDir, runID+".tar.gz")} {
+			if _, err := os.Stat(candidate); err == nil {
+				paths = append(paths, candidate)
+			}
+		}
+	}
+
+	for _, path := range paths {
+		var objects []*unstructured.Unstructured
+		var err error
+		if path == "-" {
+			objects, err = readManifests([]string{path})
+		} else {
+			objects, err = readBackup(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup: %w", err)
+		}
+		for _, obj := range objects {
+			backup.objects[backupKey(obj.GetNamespace(), obj.GetKind(), obj.GetName())] = obj
+		}
+		rollbackBackupSources = append(rollbackBackupSources, path)
+	}
+	return backup, nil
+}
+
+func (b *rollbackBackup) get(namespace, kind, name string) *unstructured.Unstructured {
+	return b.objects[backupKey(namespace, kind, name)]
+}
+
+// dependentsOf returns the backed up dependent objects of the namespace that
+// referenced the DeploymentConfig and have not been restored yet.
+func (b *rollbackBackup) dependentsOf(namespace, dcName string) []dependentObject {
+	var dependents []dependentObject
+	for key, obj := range b.objects {
+		if obj.GetNamespace() != namespace || b.restored[key] {
+			continue
+		}
+		for _, resource := range dependentResources {
+			if obj.GetKind() != resource.Kind {
+				continue
+			}
+			// Rewriting a copy tells whether the original referenced the DC.
+			if len(resource.Rewrite(obj.DeepCopy(), dcName, nil)) > 0 {
+				dependents = append(dependents, dependentObject{Resource: resource, Object: obj})
+			}
+		}
+	}
+	return dependents
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	var since time.Time
+	if rollbackSince != "" {
+		parsed, err := time.Parse(time.RFC3339, rollbackSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q: %w", rollbackSince, err)
+		}
+		since = parsed
+	}
+
+	backup, err := loadRollbackBackup(rollbackBackupPaths, rollbackRunID)
+	if err != nil {
+		return err
+	}
+
+	dynamicClient, err := connectToCluster()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Without any project flag every non-reserved namespace is searched.
+	var namespaces []string
+	if len(openShiftProjects) > 0 || allProjects || projectSelector != "" {
+		namespaces, err = resolveProjects(dynamicClient, openShiftProjects, allProjects, projectSelector)
+		if err != nil {
+			return fmt.Errorf("error validating projects: %w", err)
+		}
+		recordProjectScope(namespaces)
+	}
+
+	deployments, err := findGeneratedDeployments(ctx, dynamicClient, namespaces, rollbackRunID, since)
+	if err != nil {
+		return err
+	}
+
+	for i := range deployments {
+		rollbackResults = append(rollbackResults, rollbackDeployment(ctx, dynamicClient, &deployments[i], backup))
+	}
+
+	if err := generateRollbackReport(rollbackReportPath); err != nil {
+		return fmt.Errorf("error generating PDF report: %w", err)
+	}
+
+	return nil
+}
+
+// findGeneratedDeployments lists the Deployments created by the migration in
+// the namespaces, or in every non-reserved namespace when none are given,
+// optionally restricted to a run ID and to migrations at or after since.
+func findGeneratedDeployments(ctx context.Context, client dynamic.Interface, namespaces []string, runID string, since time.Time) ([]unstructured.Unstructured, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var generated []unstructured.Unstructured
+	for _, namespace := range namespaces {
+		list, err := client.Resource(deploymentRes).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error listing Deployments in namespace %q: %w", namespace, err)
+		}
+		for _, deployment := range list.Items {
+			if isReservedNamespace(deployment.GetNamespace()) {
+				continue
+			}
+			annotations := deployment.GetAnnotations()
+			if annotations[generatedByAnnotation] != generatedByValue {
+				continue
+			}
+			if runID != "" && annotations[migrationRunIDAnnotation] != runID {
+				continue
+			}
+			if !since.IsZero() {
+				migrated, err := time.Parse(time.RFC3339, annotations[migrationTimestampAnnotation])
+				if err != nil || migrated.Before(since) {
+					continue
+				}
+			}
+			generated = append(generated, deployment)
+		}
+	}
+	return generated, nil
+}
+
+// rollbackDeployment brings the DeploymentConfig of a generated Deployment
+// back, restores the dependent objects that referenced it and finally deletes
+// or scales down the Deployment. Nothing is changed in dry-run mode.
+func rollbackDeployment(ctx context.Context, client dynamic.Interface, deployment *unstructured.Unstructured, backup *rollbackBackup) RollbackResult {
+	namespace, name := deployment.GetNamespace(), deployment.GetName()
+	result := RollbackResult{
+		Namespace: namespace,
+		Name:      name,
+		RunID:     deployment.GetAnnotations()[migrationRunIDAnnotation],
+	}
+
+	do := func(action string, fn func() error) error {
+		if rollbackDryRun {
+			result.Actions = append(result.Actions, "would "+action)
+			return nil
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+		result.Actions = append(result.Actions, action)
+		logRollbackMessage(fmt.Sprintf("Rollback of Deployment %s in project %s: %s", name, namespace, action))
+		return nil
+	}
+	fail := func(err error) RollbackResult {
+		result.Error = err.Error()
+		logRollbackMessage(fmt.Sprintf("Rollback of Deployment %s in project %s failed: %v", name, namespace, err))
+		return result
+	}
+
+	if err := restoreDeploymentConfig(ctx, client, deployment, backup.get(namespace, "DeploymentConfig", name), do); err != nil {
+		return fail(err)
+	}
+
+	for _, dependent := range backup.dependentsOf(namespace, name) {
+		obj := dependent.Object
+		action := fmt.Sprintf("restore %s %s from backup", dependent.Resource.Kind, obj.GetName())
+		if err := do(action, func() error { return restoreObject(ctx, client, dependent) }); err != nil {
+			return fail(err)
+		}
+		backup.restored[backupKey(namespace, obj.GetKind(), obj.GetName())] = true
+	}
+
+	if rollbackDeleteDeployments {
+		err := do("delete Deployment", func() error {
+			return client.Resource(deploymentRes).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		})
+		if err != nil {
+			return fail(fmt.Errorf("error deleting Deployment: %w", err))
+		}
+	} else {
+		err := do("scale Deployment to 0 replicas", func() error {
+			return patchSpec(ctx, client, deploymentRes, namespace, name, map[string]interface{}{"replicas": 0})
+		})
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	return result
+}
+
+// restoreDeploymentConfig recreates the DeploymentConfig from its backup when
+// it was deleted, or resumes it with the backed up replicas and triggers, and
+// waits for it to become available. Without a backup the DC is resumed with
+// the current replica count of the Deployment.
+func restoreDeploymentConfig(ctx context.Context, client dynamic.Interface, deployment, backupDC *unstructured.Unstructured, do func(string, func() error) error) error {
+	namespace, name := deployment.GetNamespace(), deployment.GetName()
+
+	_, err := client.Resource(deploymentConfigRes).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		if backupDC == nil {
+			return fmt.Errorf("DeploymentConfig %s no longer exists and no backup of it was provided", name)
+		}
+		manifest := backupDC.DeepCopy()
+		cleanServerFields(manifest)
+		err = do("recreate DeploymentConfig from backup", func() error {
+			_, err := client.Resource(deploymentConfigRes).Namespace(namespace).Create(ctx, manifest, metav1.CreateOptions{})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("error recreating DeploymentConfig %s: %w", name, err)
+		}
+	case err != nil:
+		return fmt.Errorf("error getting DeploymentConfig %s: %w", name, err)
+	default:
+		spec := map[string]interface{}{"paused": false}
+		var action string
+		if backupDC != nil {
+			replicas, found, _ := unstructured.NestedInt64(backupDC.Object, "spec", "replicas")
+			if !found {
+				replicas = 1
+			}
+			spec["replicas"] = replicas
+			triggers, found, _ := unstructured.NestedSlice(backupDC.Object, "spec", "triggers")
+			if found {
+				spec["triggers"] = triggers
+			}
+			action = fmt.Sprintf("resume DeploymentConfig with %d replicas and %d triggers from backup", replicas, len(triggers))
+		} else {
+			replicas, found, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+			if !found {
+				replicas = 1
+			}
+			spec["replicas"] = replicas
+			action = fmt.Sprintf("resume DeploymentConfig with %d replicas taken from the Deployment (no backup)", replicas)
+		}
+		if err := do(action, func() error {
+			return patchSpec(ctx, client, deploymentConfigRes, namespace, name, spec)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return do("wait for DeploymentConfig to become available", func() error {
+		return waitForAvailable(ctx, client, deploymentConfigRes, namespace, name, rollbackTimeout)
+	})
+}
+
+// restoreObject puts a backed up object back in place, creating it if it was
+// deleted in the meantime.
+func restoreObject(ctx context.Context, client dynamic.Interface, dependent dependentObject) error {
+	obj := dependent.Object.DeepCopy()
+	cleanServerFields(obj)
+	resource := client.Resource(dependent.Resource.GVR).Namespace(obj.GetNamespace())
+
+	current, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = resource.Create(ctx, obj, metav1.CreateOptions{})
+	} else if err == nil {
+		obj.SetResourceVersion(current.GetResourceVersion())
+		_, err = resource.Update(ctx, obj, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("error restoring %s %s in namespace %s: %w", dependent.Resource.Kind, obj.GetName(), obj.GetNamespace(), err)
+	}
+	return nil
+}
+
+func logRollbackMessage(message string) {
+	fmt.Println(message)
+	if err := logMessage(message); err != nil {
+		fmt.Printf("Failed to log message: %v\n", err)
+	}
+}
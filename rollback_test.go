@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newRollbackClient(t *testing.T, runID string) (*dynamicfake.FakeDynamicClient, *unstructured.Unstructured, *unstructured.Unstructured) {
+	dc := newTriggeredDC(true, withField(int64(3), "spec", "replicas"))
+
+	// The live DC was paused and scaled down by the cutover.
+	live := dc.DeepCopy()
+	assert.NoError(t, unstructured.SetNestedField(live.Object, int64(0), "spec", "replicas"))
+	assert.NoError(t, unstructured.SetNestedField(live.Object, true, "spec", "paused"))
+	unstructured.RemoveNestedField(live.Object, "spec", "triggers")
+	assert.NoError(t, unstructured.SetNestedMap(live.Object, map[string]interface{}{
+		"availableReplicas": int64(3),
+		"conditions":        []interface{}{map[string]interface{}{"type": "Available", "status": "True"}},
+	}, "status"))
+
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+	annotations := deployment.GetAnnotations()
+	annotations[migrationRunIDAnnotation] = runID
+	deployment.SetAnnotations(annotations)
+
+	service := newDependent("v1", "Service", "web", map[string]interface{}{
+		"selector": map[string]interface{}{"app": "test-app"},
+	})
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		deploymentConfigRes:                   "DeploymentConfigList",
+		deploymentRes:                         "DeploymentList",
+		{Version: "v1", Resource: "services"}: "ServiceList",
+	}, live, deployment, service)
+	return client, dc, deployment
+}
+
+func newRollbackBackup(dc *unstructured.Unstructured) *rollbackBackup {
+	service := newDependent("v1", "Service", "web", map[string]interface{}{
+		"selector": map[string]interface{}{dcPodLabel: dc.GetName()},
+	})
+	return &rollbackBackup{
+		objects: map[string]*unstructured.Unstructured{
+			backupKey(dc.GetNamespace(), "DeploymentConfig", dc.GetName()): dc,
+			backupKey(service.GetNamespace(), "Service", "web"):            service,
+		},
+		restored: map[string]bool{},
+	}
+}
+
+func setRollbackTestGlobals(t *testing.T, dryRun bool) {
+	logFilePath = filepath.Join(t.TempDir(), "log.txt")
+	cutoverPollInterval = time.Millisecond
+	rollbackTimeout = 50 * time.Millisecond
+	rollbackDryRun = dryRun
+	rollbackDeleteDeployments = true
+}
+
+func TestFindGeneratedDeployments(t *testing.T) {
+	client, _, _ := newRollbackClient(t, "run-1")
+	ctx := context.Background()
+
+	deployments, err := findGeneratedDeployments(ctx, client, []string{"test-namespace"}, "", time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, deployments, 1)
+
+	deployments, err = findGeneratedDeployments(ctx, client, nil, "run-2", time.Time{})
+	assert.NoError(t, err)
+	assert.Empty(t, deployments)
+
+	deployments, err = findGeneratedDeployments(ctx, client, nil, "", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, deployments)
+}
+
+func TestRollbackDeploymentDryRun(t *testing.T) {
+	setRollbackTestGlobals(t, true)
+	client, dc, deployment := newRollbackClient(t, "run-1")
+
+	result := rollbackDeployment(context.Background(), client, deployment, newRollbackBackup(dc))
+	assert.Empty(t, result.Error)
+	assert.Equal(t, "run-1", result.RunID)
+	assert.Equal(t, []string{
+		"would resume DeploymentConfig with 3 replicas and 2 triggers from backup",
+		"would wait for DeploymentConfig to become available",
+		"would restore Service web from backup",
+		"would delete Deployment",
+	}, result.Actions)
+
+	_, err := client.Resource(deploymentRes).Namespace("test-namespace").Get(context.Background(), "test-dc", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestRollbackDeployment(t *testing.T) {
+	setRollbackTestGlobals(t, false)
+	client, dc, deployment := newRollbackClient(t, "run-1")
+	ctx := context.Background()
+
+	result := rollbackDeployment(ctx, client, deployment, newRollbackBackup(dc))
+	assert.Empty(t, result.Error)
+	assert.Len(t, result.Actions, 4)
+
+	live, err := client.Resource(deploymentConfigRes).Namespace("test-namespace").Get(ctx, "test-dc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	replicas, _, _ := unstructured.NestedInt64(live.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+	paused, _, _ := unstructured.NestedBool(live.Object, "spec", "paused")
+	assert.False(t, paused)
+	triggers, _, _ := unstructured.NestedSlice(live.Object, "spec", "triggers")
+	assert.Len(t, triggers, 2)
+
+	service, err := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "services"}).Namespace("test-namespace").Get(ctx, "web", metav1.GetOptions{})
+	assert.NoError(t, err)
+	selector, _, _ := unstructured.NestedStringMap(service.Object, "spec", "selector")
+	assert.Equal(t, map[string]string{dcPodLabel: "test-dc"}, selector)
+
+	_, err = client.Resource(deploymentRes).Namespace("test-namespace").Get(ctx, "test-dc", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestRollbackDeploymentWithoutDeploymentConfig(t *testing.T) {
+	setRollbackTestGlobals(t, false)
+	client, _, deployment := newRollbackClient(t, "run-1")
+	ctx := context.Background()
+	assert.NoError(t, client.Resource(deploymentConfigRes).Namespace("test-namespace").Delete(ctx, "test-dc", metav1.DeleteOptions{}))
+
+	result := rollbackDeployment(ctx, client, deployment, &rollbackBackup{objects: map[string]*unstructured.Unstructured{}, restored: map[string]bool{}})
+	assert.Contains(t, result.Error, "no backup")
+
+	// The Deployment is kept when its DeploymentConfig cannot be restored.
+	_, err := client.Resource(deploymentRes).Namespace("test-namespace").Get(ctx, "test-dc", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Readiness tells how much work migrating a DeploymentConfig takes.
+type Readiness string
+
+const (
+	ReadinessAutomatic   Readiness = "automatic"
+	ReadinessNeedsReview Readiness = "needs review"
+	ReadinessManual      Readiness = "manual"
+)
+
+// readinessRank orders the readiness categories from least to most work.
+var readinessRank = map[Readiness]int{
+	ReadinessAutomatic:   0,
+	ReadinessNeedsReview: 1,
+	ReadinessManual:      2,
+}
+
+// complexityFactor is a DeploymentConfig feature that makes the migration
+// harder. Its weight adds to the complexity score and its readiness is the
+// least a DeploymentConfig with the feature can be categorized as.
+type complexityFactor struct {
+	Name      string
+	Weight    int
+	Readiness Readiness
+	Detect    func(dc *unstructured.Unstructured) bool
+}
+
+var complexityFactors = []complexityFactor{
+	{Name: "triggers", Weight: 1, Readiness: ReadinessAutomatic, Detect: hasTriggers},
+	{Name: "placeholder images", Weight: 1, Readiness: ReadinessAutomatic, Detect: hasPlaceholderImages},
+	{Name: "manual image triggers", Weight: 2, Readiness: ReadinessNeedsReview, Detect: func(dc *unstructured.Unstructured) bool {
+		return len(manualImageTriggers(dc)) > 0
+	}},
+	{Name: "selector repairs", Weight: 2, Readiness: ReadinessNeedsReview, Detect: func(dc *unstructured.Unstructured) bool {
+		return len(analyzeSelector(dc, nil)) > 0
+	}},
+	{Name: "unmapped spec fields", Weight: 2, Readiness: ReadinessNeedsReview, Detect: func(dc *unstructured.Unstructured) bool {
+		return len(unmappedSpecFields(dc)) > 0
+	}},
+	{Name: "auto rollbacks", Weight: 2, Readiness: ReadinessNeedsReview, Detect: hasAutoRollbacks},
+	{Name: "execNewPod hooks", Weight: 3, Readiness: ReadinessNeedsReview, Detect: hasExecNewPodHooks},
+	{Name: "tagImages hooks", Weight: 5, Readiness: ReadinessManual, Detect: hasTagImagesHooks},
+	{Name: "custom strategy", Weight: 8, Readiness: ReadinessManual, Detect: usesCustomStrategies},
+}
+
+// ScanResult is the assessment of one DeploymentConfig.
+type ScanResult struct {
+	Namespace            string
+	DeploymentConfigName string
+	Score                int
+	Readiness            Readiness
+	Factors              []string
+}
+
+var scanResults []ScanResult
+
+func runScan(cmd *cobra.Command, args []string) error {
+	if _, ok := scanSortKeys[scanSortBy]; !ok {
+		return fmt.Errorf("invalid --sort-by %q: must be score, readiness, namespace or name", scanSortBy)
+	}
+	if len(openShiftProjects) == 0 && !allProjects && projectSelector == "" {
+		return fmt.Errorf("either --projects, --all-projects or --project-selector must be specified")
+	}
+
+	dynamicClient, err := connectToCluster()
+	if err != nil {
+		return err
+	}
+
+	projects, err := resolveProjects(dynamicClient, openShiftProjects, allProjects, projectSelector)
+	if err != nil {
+		return fmt.Errorf("error validating projects: %w", err)
+	}
+	recordProjectScope(projects)
+
+	for _, namespace := range projects {
+		err := getDCs(dynamicClient, namespace, func(dcs []unstructured.Unstructured) error {
+			for i := range dcs {
+				scanResults = append(scanResults, scanDC(&dcs[i], namespace))
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error scanning project %s: %w", namespace, err)
+		}
+	}
+
+	sortScanResults(scanResults, scanSortBy)
+	printScanInventory(os.Stdout, scanResults)
+
+	if err := generateScanReport(scanReportPath); err != nil {
+		return fmt.Errorf("error generating PDF report: %w", err)
+	}
+
+	return nil
+}
+
+// scanDC scores the DeploymentConfig with the complexity factors it has.
+func scanDC(dc *unstructured.Unstructured, namespace string) ScanResult {
+	result := ScanResult{
+		Namespace:            namespace,
+		DeploymentConfigName: dc.GetName(),
+		Readiness:            ReadinessAutomatic,
+	}
+	for _, factor := range complexityFactors {
+		if !factor.Detect(dc) {
+			continue
+		}
+		result.Score += factor.Weight
+		result.Factors = append(result.Factors, fmt.Sprintf("%s (+%d)", factor.Name, factor.Weight))
+		if readinessRank[factor.Readiness] > readinessRank[result.Readiness] {
+			result.Readiness = factor.Readiness
+		}
+	}
+	return result
+}
+
+func hasExecNewPodHooks(dc *unstructured.Unstructured) bool {
+	for _, hook := range getLifecycleHooks(dc) {
+		if hook.ExecNewPod != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTagImagesHooks(dc *unstructured.Unstructured) bool {
+	for _, hook := range getLifecycleHooks(dc) {
+		if len(hook.TagImages) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPlaceholderImages reports whether a container has no image of its own
+// and relies on an ImageChange trigger to fill it in.
+func hasPlaceholderImages(dc *unstructured.Unstructured) bool {
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(dc.Object, "spec", "template", "spec", field)
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if image, _ := container["image"].(string); strings.TrimSpace(image) == "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scanSortKeys compares two scan results for each --sort-by value. Ties are
+// broken by namespace and name.
+var scanSortKeys = map[string]func(a, b ScanResult) int{
+	"score": func(a, b ScanResult) int { return b.Score - a.Score },
+	"readiness": func(a, b ScanResult) int {
+		if rank := readinessRank[b.Readiness] - readinessRank[a.Readiness]; rank != 0 {
+			return rank
+		}
+		return b.Score - a.Score
+	},
+	"namespace": func(a, b ScanResult) int { return 0 },
+	"name":      func(a, b ScanResult) int { return strings.Compare(a.DeploymentConfigName, b.DeploymentConfigName) },
+}
+
+func sortScanResults(results []ScanResult, sortBy string) {
+	compare := scanSortKeys[sortBy]
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if c := compare(a, b); c != 0 {
+			return c < 0
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.DeploymentConfigName < b.DeploymentConfigName
+	})
+}
+
+// scanTotals counts the scanned DeploymentConfigs per readiness category and
+// sums their scores.
+func scanTotals(results []ScanResult) (map[Readiness]int, int) {
+	counts := map[Readiness]int{}
+	score := 0
+	for _, result := range results {
+		counts[result.Readiness]++
+		score += result.Score
+	}
+	return counts, score
+}
+
+func scanSummary(results []ScanResult) string {
+	counts, score := scanTotals(results)
+	return fmt.Sprintf("%d DeploymentConfigs: %d automatic, %d needs review, %d manual; total score %d",
+		len(results), counts[ReadinessAutomatic], counts[ReadinessNeedsReview], counts[ReadinessManual], score)
+}
+
+func printScanInventory(out io.Writer, results []ScanResult) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tDEPLOYMENTCONFIG\tSCORE\tREADINESS\tFACTORS")
+	for _, result := range results {
+		factors := strings.Join(result.Factors, ", ")
+		if factors == "" {
+			factors = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", result.Namespace, result.DeploymentConfigName, result.Score, result.Readiness, factors)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\nTotal: %s\n", scanSummary(results))
+}
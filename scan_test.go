@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newScanDC(strategy map[string]interface{}) *unstructured.Unstructured {
+	return newTestDC(
+		withField(strategy, "spec", "strategy"),
+		withField([]interface{}{map[string]interface{}{"type": "ConfigChange"}}, "spec", "triggers"),
+	)
+}
+
+func TestScanDC(t *testing.T) {
+	tests := []struct {
+		name      string
+		dc        *unstructured.Unstructured
+		score     int
+		readiness Readiness
+		factors   []string
+	}{
+		{
+			name:      "Plain rolling DC",
+			dc:        newScanDC(map[string]interface{}{"type": "Rolling"}),
+			score:     1,
+			readiness: ReadinessAutomatic,
+			factors:   []string{"triggers (+1)"},
+		},
+		{
+			name: "Auto rollbacks",
+			dc: newScanDC(map[string]interface{}{
+				"type":          "Rolling",
+				"rollingParams": map[string]interface{}{"autoRollbackEnabled": true},
+			}),
+			score:     3,
+			readiness: ReadinessNeedsReview,
+			factors:   []string{"triggers (+1)", "auto rollbacks (+2)"},
+		},
+		{
+			name:      "Custom strategy",
+			dc:        newScanDC(map[string]interface{}{"type": "Custom", "customParams": map[string]interface{}{"image": "deployer"}}),
+			score:     11,
+			readiness: ReadinessManual,
+			factors:   []string{"triggers (+1)", "unmapped spec fields (+2)", "custom strategy (+8)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := scanDC(tt.dc, "test-namespace")
+			assert.Equal(t, tt.score, result.Score)
+			assert.Equal(t, tt.readiness, result.Readiness)
+			assert.Equal(t, tt.factors, result.Factors)
+		})
+	}
+}
+
+func TestScanDCHooks(t *testing.T) {
+	result := scanDC(newHookedDC(), "test-namespace")
+	assert.Contains(t, result.Factors, "execNewPod hooks (+3)")
+	assert.Contains(t, result.Factors, "tagImages hooks (+5)")
+	assert.Equal(t, ReadinessManual, result.Readiness)
+}
+
+func TestHasPlaceholderImages(t *testing.T) {
+	assert.True(t, hasPlaceholderImages(newTriggeredDC(true)))
+	assert.False(t, hasPlaceholderImages(newScanDC(nil)))
+}
+
+func TestSortScanResults(t *testing.T) {
+	results := []ScanResult{
+		{Namespace: "b", DeploymentConfigName: "web", Score: 1, Readiness: ReadinessAutomatic},
+		{Namespace: "a", DeploymentConfigName: "worker", Score: 4, Readiness: ReadinessNeedsReview},
+		{Namespace: "a", DeploymentConfigName: "api", Score: 5, Readiness: ReadinessManual},
+		{Namespace: "b", DeploymentConfigName: "cron", Score: 6, Readiness: ReadinessNeedsReview},
+	}
+	names := func() []string {
+		var names []string
+		for _, result := range results {
+			names = append(names, result.DeploymentConfigName)
+		}
+		return names
+	}
+
+	sortScanResults(results, "score")
+	assert.Equal(t, []string{"cron", "api", "worker", "web"}, names())
+	sortScanResults(results, "readiness")
+	assert.Equal(t, []string{"api", "cron", "worker", "web"}, names())
+	sortScanResults(results, "namespace")
+	assert.Equal(t, []string{"api", "worker", "cron", "web"}, names())
+	sortScanResults(results, "name")
+	assert.Equal(t, []string{"api", "cron", "web", "worker"}, names())
+}
+
+func TestPrintScanInventory(t *testing.T) {
+	var out bytes.Buffer
+	printScanInventory(&out, []ScanResult{
+		{Namespace: "a", DeploymentConfigName: "api", Score: 5, Readiness: ReadinessManual, Factors: []string{"custom strategy (+5)"}},
+		{Namespace: "a", DeploymentConfigName: "web", Readiness: ReadinessAutomatic},
+	})
+
+	assert.Contains(t, out.String(), "NAMESPACE")
+	assert.Contains(t, out.String(), "custom strategy (+5)")
+	assert.Contains(t, out.String(), "Total: 2 DeploymentConfigs: 1 automatic, 0 needs review, 1 manual; total score 5")
+}
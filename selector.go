@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+// stableSelectorLabel is added to the selector and the pod template when
+// removing the deploymentconfig label leaves a selector that is empty or
+// selects the pods of another DeploymentConfig, and the pod template has no
+// label of its own to select on. Its value is the Deployment name, so it
+// stays the same across runs, which matters since Deployment selectors are
+// immutable.
+const stableSelectorLabel = "app.kubernetes.io/name"
+
+// preferredSelectorLabels are the pod template labels a selector falls back
+// to first, as they usually identify a single application.
+var preferredSelectorLabels = []string{stableSelectorLabel, "app.kubernetes.io/instance", "app", "name"}
+
+// podLabelIndex holds the pod template labels, without the deploymentconfig
+// label, of the DeploymentConfigs of a namespace or Template by name.
+// Selectors are repaired against it so that a Deployment never selects the
+// pods of a sibling, which the deploymentconfig label used to tell apart.
+type podLabelIndex map[string]map[string]interface{}
+
+// add records the pod template labels of the DeploymentConfig.
+func (idx podLabelIndex) add(dc *unstructured.Unstructured) {
+	templateLabels, _, _ := unstructured.NestedMap(dc.Object, "spec", "template", "metadata", "labels")
+	delete(templateLabels, "deploymentconfig")
+	idx[dc.GetName()] = templateLabels
+}
+
+// sharesLabel reports whether a DeploymentConfig other than name has the
+// label k=v in its pod template.
+func (idx podLabelIndex) sharesLabel(name, k string, v interface{}) bool {
+	for other, templateLabels := range idx {
+		if other != name && templateLabels[k] == v {
+			return true
+		}
+	}
+	return false
+}
+
+// selectedSiblings returns the DeploymentConfigs other than name whose pods
+// the selector matches.
+func (idx podLabelIndex) selectedSiblings(name string, selector map[string]interface{}) []string {
+	var siblings []string
+	for other, templateLabels := range idx {
+		if other == name || len(templateLabels) == 0 {
+			continue
+		}
+		matches := true
+		for k, v := range selector {
+			if templateLabels[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			siblings = append(siblings, other)
+		}
+	}
+	sort.Strings(siblings)
+	return siblings
+}
+
+// indexPodLabels indexes the pod template labels of every DeploymentConfig of
+// the namespace, including the ones that are filtered out or skipped, as
+// their pods keep running.
+func indexPodLabels(client dynamic.Interface, namespace string) (podLabelIndex, error) {
+	siblings := podLabelIndex{}
+	err := getDCs(client, namespace, func(dcs []unstructured.Unstructured) error {
+		for i := range dcs {
+			siblings.add(&dcs[i])
+		}
+		return nil
+	})
+	return siblings, err
+}
+
+// repairSelector makes the Deployment selector a non-empty subset of the pod
+// template labels. Selector labels missing from the template are added to it,
+// and selector labels the template sets to another value are dropped. A
+// selector left empty, or matching the pods of a sibling in the index, falls
+// back to a label of the pod template that no sibling carries, which the DC
+// pods carry too. It returns a finding per repair. Only when the template has
+// no such label is the stableSelectorLabel synthesized, which is reported as
+// a synthesized-selector finding.
+func repairSelector(deployment *unstructured.Unstructured, siblings podLabelIndex) ([]Finding, error) {
+	selector, _, err := unstructured.NestedMap(deployment.Object, "spec", "selector", "matchLabels")
+	if err != nil {
+		return nil, fmt.Errorf("error getting selector: %w", err)
+	}
+	if selector == nil {
+		selector = map[string]interface{}{}
+	}
+	templateLabels, _, err := unstructured.NestedMap(deployment.Object, "spec", "template", "metadata", "labels")
+	if err != nil {
+		return nil, fmt.Errorf("error getting template labels: %w", err)
+	}
+	if templateLabels == nil {
+		templateLabels = map[string]interface{}{}
+	}
+
+	var keys []string
+	for k := range selector {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var findings []Finding
+	for _, k := range keys {
+		v := selector[k]
+		templateValue, ok := templateLabels[k]
+		switch {
+		case !ok:
+			templateLabels[k] = v
+			findings = append(findings, newFinding(codeSelectorRepair, templateLabelField(k),
+				fmt.Sprintf("Added label %s=%v to the pod template so that it matches the selector", k, v)))
+		case templateValue != v:
+			delete(selector, k)
+			findings = append(findings, newFinding(codeSelectorRepair, selectorLabelField(k),
+				fmt.Sprintf("Removed %s=%v from the selector because the pod template sets %s=%v", k, v, k, templateValue)))
+		}
+	}
+
+	name := deployment.GetName()
+	var reason string
+	if len(selector) == 0 {
+		reason = "Selector was empty without the deploymentconfig label"
+	} else if selected := siblings.selectedSiblings(name, selector); len(selected) > 0 {
+		reason = fmt.Sprintf("Selector %s also selects the pods of DeploymentConfig %s without the deploymentconfig label", labels.Set(toStringMap(selector)), strings.Join(selected, ", "))
+	}
+	if reason != "" {
+		if k := existingSelectorLabel(templateLabels, name, siblings); k != "" {
+			selector[k] = templateLabels[k]
+			findings = append(findings, newFinding(codeSelectorRepair, selectorLabelField(k),
+				fmt.Sprintf("%s; selecting on the pod template label %s=%v", reason, k, templateLabels[k])))
+		} else {
+			templateLabels[stableSelectorLabel] = name
+			selector[stableSelectorLabel] = name
+			findings = append(findings, newFinding(codeSynthesizedSelector, selectorLabelField(stableSelectorLabel),
+				fmt.Sprintf("%s and the pod template has no label of its own; added %s=%v to both, which the DeploymentConfig pods do not carry", reason, stableSelectorLabel, name)))
+		}
+	}
+
+	if err := unstructured.SetNestedMap(deployment.Object, selector, "spec", "selector", "matchLabels"); err != nil {
+		return nil, fmt.Errorf("error setting selector: %w", err)
+	}
+	if err := unstructured.SetNestedMap(deployment.Object, templateLabels, "spec", "template", "metadata", "labels"); err != nil {
+		return nil, fmt.Errorf("error setting template labels: %w", err)
+	}
+	return findings, nil
+}
+
+// selectorLabelField returns the path of a selector label.
+func selectorLabelField(key string) string {
+	return fmt.Sprintf("spec.selector.matchLabels[%s]", key)
+}
+
+// templateLabelField returns the path of a pod template label.
+func templateLabelField(key string) string {
+	return fmt.Sprintf("spec.template.metadata.labels[%s]", key)
+}
+
+// existingSelectorLabel picks the pod template label a selector falls back
+// to: one of the preferredSelectorLabels, or else the first label by name,
+// skipping the labels a sibling carries with the same value. It returns an
+// empty string when the template has no such label.
+func existingSelectorLabel(templateLabels map[string]interface{}, name string, siblings podLabelIndex) string {
+	var keys []string
+	for k := range templateLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range append(append([]string{}, preferredSelectorLabels...), keys...) {
+		if v, ok := templateLabels[k]; ok && !siblings.sharesLabel(name, k, v) {
+			return k
+		}
+	}
+	return ""
+}
+
+func toStringMap(m map[string]interface{}) map[string]string {
+	converted := make(map[string]string, len(m))
+	for k, v := range m {
+		converted[k] = fmt.Sprint(v)
+	}
+	return converted
+}
+
+// analyzeSelector describes the repairs the conversion makes to the selector
+// of the DeploymentConfig, including the selector label it has to
+// synthesize, if any.
+func analyzeSelector(dc *unstructured.Unstructured, siblings podLabelIndex) []Finding {
+	spec, found, _ := unstructured.NestedMap(dc.Object, "spec")
+	if !found {
+		return nil
+	}
+	scratch := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	scratch.SetName(dc.GetName())
+	if err := setSelector(spec, scratch); err != nil {
+		return nil
+	}
+	if err := setTemplate(spec, scratch); err != nil {
+		return nil
+	}
+	findings, _ := repairSelector(scratch, siblings)
+	return findings
+}
+
+// selectorOverlaps warns about the other Deployments of the namespace whose
+// selector matches the pods of the converted Deployment, or whose pods the
+// converted Deployment would select.
+func selectorOverlaps(client dynamic.Interface, deployment *unstructured.Unstructured) ([]Finding, error) {
+	ctx := context.Background()
+	converted := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(deployment.Object, converted); err != nil {
+		return nil, fmt.Errorf("error decoding Deployment %s: %w", deployment.GetName(), err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(converted.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing selector of Deployment %s: %w", deployment.GetName(), err)
+	}
+
+	list, err := client.Resource(deploymentRes).Namespace(deployment.GetNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing Deployments in namespace %s: %w", deployment.GetNamespace(), err)
+	}
+
+	var warnings []Finding
+	for _, item := range list.Items {
+		if item.GetName() == deployment.GetName() {
+			continue
+		}
+		other := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, other); err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(other.Spec.Template.Labels)) {
+			warnings = append(warnings, newFinding(codeSelectorOverlap, "spec.selector",
+				fmt.Sprintf("selector %s also matches the pods of Deployment %s", selector, other.Name)))
+		}
+		if otherSelector, err := metav1.LabelSelectorAsSelector(other.Spec.Selector); err == nil && !otherSelector.Empty() && otherSelector.Matches(labels.Set(converted.Spec.Template.Labels)) {
+			warnings = append(warnings, newFinding(codeSelectorOverlap, "spec.template.metadata.labels",
+				fmt.Sprintf("the selector %s of Deployment %s matches the pods of this Deployment", otherSelector, other.Name)))
+		}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newSelectorDeployment(name string, selector, templateLabels map[string]interface{}) *unstructured.Unstructured {
+	return newTestDC(
+		withField("apps/v1", "apiVersion"),
+		withField("Deployment", "kind"),
+		withField(name, "metadata", "name"),
+		withField(map[string]interface{}{"matchLabels": selector}, "spec", "selector"),
+		withField(templateLabels, "spec", "template", "metadata", "labels"),
+	)
+}
+
+func TestRepairSelector(t *testing.T) {
+	tests := []struct {
+		name             string
+		selector         map[string]interface{}
+		templateLabels   map[string]interface{}
+		expectedSelector map[string]interface{}
+		expectedLabels   map[string]interface{}
+		findings         int
+	}{
+		{
+			"Consistent selector",
+			map[string]interface{}{"app": "web"},
+			map[string]interface{}{"app": "web", "tier": "frontend"},
+			map[string]interface{}{"app": "web"},
+			map[string]interface{}{"app": "web", "tier": "frontend"},
+			0,
+		},
+		{
+			"Selector label missing from template",
+			map[string]interface{}{"app": "web", "tier": "frontend"},
+			map[string]interface{}{"app": "web"},
+			map[string]interface{}{"app": "web", "tier": "frontend"},
+			map[string]interface{}{"app": "web", "tier": "frontend"},
+			1,
+		},
+		{
+			"Conflicting template label",
+			map[string]interface{}{"app": "web", "tier": "frontend"},
+			map[string]interface{}{"app": "web", "tier": "backend"},
+			map[string]interface{}{"app": "web"},
+			map[string]interface{}{"app": "web", "tier": "backend"},
+			1,
+		},
+		{
+			"Empty selector",
+			map[string]interface{}{},
+			map[string]interface{}{"app": "web", "tier": "frontend"},
+			map[string]interface{}{"app": "web"},
+			map[string]interface{}{"app": "web", "tier": "frontend"},
+			1,
+		},
+		{
+			"Empty selector without preferred labels",
+			map[string]interface{}{},
+			map[string]interface{}{"tier": "frontend", "component": "web"},
+			map[string]interface{}{"component": "web"},
+			map[string]interface{}{"tier": "frontend", "component": "web"},
+			1,
+		},
+		{
+			"Empty selector and template labels",
+			map[string]interface{}{},
+			map[string]interface{}{},
+			map[string]interface{}{stableSelectorLabel: "web"},
+			map[string]interface{}{stableSelectorLabel: "web"},
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := newSelectorDeployment("web", tt.selector, tt.templateLabels)
+			findings, err := repairSelector(deployment, nil)
+			assert.NoError(t, err)
+			assert.Len(t, findings, tt.findings)
+			assert.Equal(t, len(tt.templateLabels) == 0, ConversionInfo{Findings: findings}.hasFinding(codeSynthesizedSelector))
+
+			selector, _, _ := unstructured.NestedMap(deployment.Object, "spec", "selector", "matchLabels")
+			assert.Equal(t, tt.expectedSelector, selector)
+			labels, _, _ := unstructured.NestedMap(deployment.Object, "spec", "template", "metadata", "labels")
+			assert.Equal(t, tt.expectedLabels, labels)
+		})
+	}
+}
+
+func TestConvertDCOnlySelectingOnDeploymentConfigLabel(t *testing.T) {
+	dc := newHookedDC()
+	assert.NoError(t, unstructured.SetNestedMap(dc.Object, map[string]interface{}{"deploymentconfig": "test-dc"}, "spec", "selector"))
+
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, validateDeployment(deployment))
+
+	selector, _, _ := unstructured.NestedMap(deployment.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]interface{}{"app": "test-app"}, selector)
+	findings := analyzeSelector(dc, nil)
+	assert.Len(t, findings, 1)
+	assert.Equal(t, codeSelectorRepair, findings[0].Code)
+	assert.Equal(t, "spec.selector.matchLabels[app]", findings[0].Field)
+}
+
+func TestConvertDCSynthesizesSelectorLabel(t *testing.T) {
+	dc := newHookedDC()
+	assert.NoError(t, unstructured.SetNestedMap(dc.Object, map[string]interface{}{"deploymentconfig": "test-dc"}, "spec", "selector"))
+	assert.NoError(t, unstructured.SetNestedMap(dc.Object, map[string]interface{}{"deploymentconfig": "test-dc"}, "spec", "template", "metadata", "labels"))
+
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+	selector, _, _ := unstructured.NestedMap(deployment.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]interface{}{stableSelectorLabel: "test-dc"}, selector)
+
+	findings := detectFindings(dc, nil)
+	assert.Len(t, ConversionInfo{Findings: findings}.findingsOf(codeSynthesizedSelector), 1)
+}
+
+func TestConvertSiblingDCsSelectDistinctPods(t *testing.T) {
+	newSibling := func(name string, templateLabels, selector map[string]interface{}) *unstructured.Unstructured {
+		templateLabels["deploymentconfig"] = name
+		selector["deploymentconfig"] = name
+		return newTestDC(
+			withField(name, "metadata", "name"),
+			withField(templateLabels, "spec", "template", "metadata", "labels"),
+			withField(selector, "spec", "selector"),
+		)
+	}
+	convert := func(dcs ...*unstructured.Unstructured) []map[string]interface{} {
+		siblings := podLabelIndex{}
+		for _, dc := range dcs {
+			siblings.add(dc)
+		}
+		var selectors []map[string]interface{}
+		for _, dc := range dcs {
+			deployment, err := convertDCtoDeployment(dc, siblings)
+			assert.NoError(t, err)
+			assert.Empty(t, validateDeployment(deployment))
+			selector, _, _ := unstructured.NestedMap(deployment.Object, "spec", "selector", "matchLabels")
+			selectors = append(selectors, selector)
+		}
+		return selectors
+	}
+
+	// Only the deploymentconfig label told the pods apart.
+	frontend := newSibling("frontend", map[string]interface{}{"env": "prod", "tier": "x"}, map[string]interface{}{})
+	backend := newSibling("backend", map[string]interface{}{"env": "prod", "tier": "x"}, map[string]interface{}{})
+	assert.Equal(t, []map[string]interface{}{
+		{stableSelectorLabel: "frontend"},
+		{stableSelectorLabel: "backend"},
+	}, convert(frontend, backend))
+	siblings := podLabelIndex{}
+	siblings.add(frontend)
+	siblings.add(backend)
+	findings := analyzeSelector(frontend, siblings)
+	assert.Len(t, findings, 1)
+	assert.Equal(t, codeSynthesizedSelector, findings[0].Code)
+
+	// A label of their own is preferred over the shared app label.
+	frontend = newSibling("frontend", map[string]interface{}{"app": "shop", "tier": "web"}, map[string]interface{}{})
+	backend = newSibling("backend", map[string]interface{}{"app": "shop", "tier": "db"}, map[string]interface{}{"app": "shop"})
+	assert.Equal(t, []map[string]interface{}{
+		{"tier": "web"},
+		{"app": "shop", "tier": "db"},
+	}, convert(frontend, backend))
+
+	// Without siblings the app label is enough.
+	assert.Equal(t, []map[string]interface{}{{"app": "shop"}}, convert(frontend))
+}
+
+func TestSelectorOverlaps(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		deploymentRes: "DeploymentList",
+	},
+		newSelectorDeployment("broad", map[string]interface{}{"tier": "frontend"}, map[string]interface{}{"tier": "frontend", "app": "other"}),
+		newSelectorDeployment("unrelated", map[string]interface{}{"app": "db"}, map[string]interface{}{"app": "db"}),
+		newSelectorDeployment("web", map[string]interface{}{"app": "web"}, map[string]interface{}{"app": "web"}),
+	)
+
+	deployment := newSelectorDeployment("web", map[string]interface{}{"tier": "frontend"}, map[string]interface{}{"tier": "frontend", "app": "web"})
+	warnings, err := selectorOverlaps(client, deployment)
+	assert.NoError(t, err)
+	assert.Equal(t, []Finding{
+		newFinding(codeSelectorOverlap, "spec.selector", "selector tier=frontend also matches the pods of Deployment broad"),
+		newFinding(codeSelectorOverlap, "spec.template.metadata.labels", "the selector tier=frontend of Deployment broad matches the pods of this Deployment"),
+	}, warnings)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// specFieldMapping describes where a DeploymentConfigSpec field ends up in the
+// converted Deployment. Fields without a Target have no Deployment equivalent.
+// Direct fields are copied verbatim; the others are handled by a dedicated
+// conversion step.
+type specFieldMapping struct {
+	Field  string
+	Target string
+	Direct bool
+}
+
+var dcSpecFieldMappings = []specFieldMapping{
+	{Field: "replicas", Target: "spec.replicas"},
+	{Field: "selector", Target: "spec.selector.matchLabels"},
+	{Field: "template", Target: "spec.template"},
+	{Field: "strategy", Target: "spec.strategy"},
+	{Field: "strategy.rollingParams.timeoutSeconds", Target: "spec.progressDeadlineSeconds"},
+	{Field: "strategy.recreateParams.timeoutSeconds", Target: "spec.progressDeadlineSeconds"},
+	{Field: "minReadySeconds", Target: "spec.minReadySeconds", Direct: true},
+	{Field: "revisionHistoryLimit", Target: "spec.revisionHistoryLimit", Direct: true},
+	{Field: "paused", Target: "spec.paused", Direct: true},
+	{Field: "triggers", Target: "metadata.annotations[" + imageTriggersAnnotation + "]"},
+	{Field: "test"},
+	{Field: "strategy.resources"},
+	{Field: "strategy.labels"},
+	{Field: "strategy.annotations"},
+	{Field: "strategy.activeDeadlineSeconds"},
+	{Field: "strategy.customParams"},
+}
+
+// copySpecFields copies the DeploymentConfigSpec fields that exist unchanged
+// in the Deployment spec.
+func copySpecFields(spec map[string]interface{}, deployment *unstructured.Unstructured) error {
+	for _, mapping := range dcSpecFieldMappings {
+		if !mapping.Direct {
+			continue
+		}
+		value, found, err := unstructured.NestedFieldCopy(spec, strings.Split(mapping.Field, ".")...)
+		if err != nil {
+			return fmt.Errorf("error getting %s: %w", mapping.Field, err)
+		}
+		if !found {
+			continue
+		}
+		if err := unstructured.SetNestedField(deployment.Object, value, strings.Split(mapping.Target, ".")...); err != nil {
+			return fmt.Errorf("error setting %s: %w", mapping.Target, err)
+		}
+	}
+	return nil
+}
+
+// unmappedSpecFields lists the fields set in the DeploymentConfig spec that
+// are not carried over to the Deployment, either because they have no
+// equivalent or because the mapping table does not know them.
+func unmappedSpecFields(dc *unstructured.Unstructured) []string {
+	spec, found, err := unstructured.NestedMap(dc.Object, "spec")
+	if err != nil || !found {
+		return nil
+	}
+
+	known := map[string]bool{}
+	var unmapped []string
+	for _, mapping := range dcSpecFieldMappings {
+		path := strings.Split(mapping.Field, ".")
+		known[path[0]] = true
+		if mapping.Target != "" {
+			continue
+		}
+		if value, found, _ := unstructured.NestedFieldNoCopy(spec, path...); found && !isZeroValue(value) {
+			unmapped = append(unmapped, "spec."+mapping.Field)
+		}
+	}
+
+	var unknown []string
+	for field := range spec {
+		if !known[field] {
+			unknown = append(unknown, "spec."+field)
+		}
+	}
+	sort.Strings(unknown)
+
+	return append(unmapped, unknown...)
+}
+
+func isZeroValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCopySpecFields(t *testing.T) {
+	dc := newTriggeredDC(true)
+	spec, _, _ := unstructured.NestedMap(dc.Object, "spec")
+	spec["minReadySeconds"] = int64(10)
+	spec["revisionHistoryLimit"] = int64(3)
+	spec["paused"] = true
+	assert.NoError(t, unstructured.SetNestedMap(dc.Object, spec, "spec"))
+
+	deployment, err := convertDCtoDeployment(dc, nil)
+	assert.NoError(t, err)
+
+	minReadySeconds, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "minReadySeconds")
+	assert.Equal(t, int64(10), minReadySeconds)
+	revisionHistoryLimit, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "revisionHistoryLimit")
+	assert.Equal(t, int64(3), revisionHistoryLimit)
+	paused, _, _ := unstructured.NestedBool(deployment.Object, "spec", "paused")
+	assert.True(t, paused)
+}
+
+func TestUnmappedSpecFields(t *testing.T) {
+	dc := newTriggeredDC(true)
+	assert.Empty(t, unmappedSpecFields(dc))
+
+	assert.NoError(t, unstructured.SetNestedField(dc.Object, true, "spec", "test"))
+	assert.NoError(t, unstructured.SetNestedField(dc.Object, false, "spec", "paused"))
+	assert.NoError(t, unstructured.SetNestedField(dc.Object, int64(600), "spec", "strategy", "activeDeadlineSeconds"))
+	assert.NoError(t, unstructured.SetNestedField(dc.Object, "value", "spec", "futureField"))
+
+	assert.Equal(t, []string{"spec.test", "spec.strategy.activeDeadlineSeconds", "spec.futureField"}, unmappedSpecFields(dc))
+}
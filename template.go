@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var templateRes = schema.GroupVersionResource{Group: "template.openshift.io", Version: "v1", Resource: "templates"}
+
+// parameterReferencePattern matches ${NAME} and ${{NAME}} template parameter
+// references.
+var parameterReferencePattern = regexp.MustCompile(`\$\{\{?[a-zA-Z0-9_]+\}?\}`)
+
+func isParameterReference(value string) bool {
+	return parameterReferencePattern.MatchString(value)
+}
+
+func isTemplate(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Kind == "Template" && (gvk.Group == "template.openshift.io" || gvk.Group == "")
+}
+
+// getTemplates lists the Templates of a namespace. Clusters without the
+// template API are treated as having no Templates.
+func getTemplates(client dynamic.Interface, namespace string) (*unstructured.UnstructuredList, error) {
+	ctx := context.Background()
+	templates, err := client.Resource(templateRes).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return &unstructured.UnstructuredList{}, nil
+	}
+	return templates, err
+}
+
+// convertTemplate rewrites the DeploymentConfigs embedded in a Template's
+// objects into Deployments, appending the Jobs generated for their lifecycle
+// hooks. Parameter references are left untouched. DeploymentConfigs left out
+// by the filters stay in the Template as they are. It returns the updated
+// Template along with a ConversionInfo per embedded DeploymentConfig. When a
+// DeploymentConfig cannot be converted, the ConversionInfos up to and
+// including it are returned with the error.
+func convertTemplate(template *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, []ConversionInfo, error) {
+	converted := template.DeepCopy()
+	objects, found, err := unstructured.NestedSlice(converted.Object, "objects")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting objects of Template %s: %w", template.GetName(), err)
+	}
+	if !found {
+		return converted, nil, nil
+	}
+
+	// The DeploymentConfigs of a Template are instantiated together, so their
+	// selectors must tell their pods apart.
+	siblings := podLabelIndex{}
+	for _, o := range objects {
+		obj, ok := o.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		dc := &unstructured.Unstructured{Object: obj}
+		if isDeploymentConfig(dc) {
+			siblings.add(dc)
+		}
+	}
+
+	var infos []ConversionInfo
+	var convertedObjects []interface{}
+	for _, o := range objects {
+		obj, ok := o.(map[string]interface{})
+		if !ok {
+			convertedObjects = append(convertedObjects, o)
+			continue
+		}
+		dc := &unstructured.Unstructured{Object: obj}
+		if !isDeploymentConfig(dc) {
+			convertedObjects = append(convertedObjects, o)
+			continue
+		}
+
+		if reason := skipReason(dc); reason != "" {
+			info := skippedConversionInfo(dc, namespace, reason)
+			info.Template = template.GetName()
+			infos = append(infos, info)
+			convertedObjects = append(convertedObjects, o)
+			continue
+		}
+
+		info := newConversionInfo(dc, namespace, siblings)
+		info.Template = template.GetName()
+		info.Findings = append(info.Findings, newFinding(codeValidationSkipped, "spec",
+			"The Deployment was not validated, since its parameter references only resolve when the Template is processed"))
+
+		deployment, err := convertDCtoDeployment(dc, siblings)
+		if err != nil {
+			return nil, append(infos, info), fmt.Errorf("error converting DeploymentConfig %s: %w", dc.GetName(), err)
+		}
+		hookJobs, hookNotes, err := convertHooksToJobs(dc, deployment)
+		if err != nil {
+			return nil, append(infos, info), fmt.Errorf("error converting lifecycle hooks of DeploymentConfig %s: %w", dc.GetName(), err)
+		}
+		info.Findings = append(info.Findings, hookNotes...)
+		info.DeploymentManifest = manifestYAML(deployment)
+		info.Outcome = OutcomeConverted
+
+		convertedObjects = append(convertedObjects, deployment.Object)
+		for _, job := range hookJobs {
+			convertedObjects = append(convertedObjects, job.Object)
+		}
+		infos = append(infos, info)
+	}
+
+	if err := unstructured.SetNestedSlice(converted.Object, convertedObjects, "objects"); err != nil {
+		return nil, nil, fmt.Errorf("error setting objects of Template %s: %w", template.GetName(), err)
+	}
+	cleanServerFields(converted)
+	return converted, infos, nil
+}
+
+// processTemplate converts a Template containing DeploymentConfigs and writes
+// it to the templates directory of the namespace. It returns false, without
+// writing anything, when the Template embeds no DeploymentConfigs. When the
+// Template cannot be converted or written, its DeploymentConfigs are recorded
+// as failed.
+func processTemplate(template *unstructured.Unstructured, namespace string) bool {
+	converted, infos, err := convertTemplate(template, namespace)
+	if err != nil {
+		logErr := logMessage(fmt.Sprintf("Error converting Template %s in project %s: %v", template.GetName(), namespace, err))
+		if logErr != nil {
+			fmt.Printf("Failed to log message: %v\n", logErr)
+		}
+		recordFailedTemplate(infos, err)
+		return true
+	}
+	if len(infos) == 0 {
+		return false
+	}
+
+	if err := saveManifestYAML(converted, filepath.Join(namespace, "templates")); err != nil {
+		logErr := logMessage(fmt.Sprintf("Error saving Template YAML for %s in project %s: %v", template.GetName(), namespace, err))
+		if logErr != nil {
+			fmt.Printf("Failed to log message: %v\n", logErr)
+		}
+		recordFailedTemplate(infos, err)
+		return true
+	}
+
+	recordConversionInfos(infos...)
+	return true
+}
+
+// recordFailedTemplate records the DeploymentConfigs of a Template that was
+// not written as failed. Skipped DeploymentConfigs stay skipped.
+func recordFailedTemplate(infos []ConversionInfo, err error) {
+	for i := range infos {
+		if infos[i].Outcome == OutcomeSkipped {
+			continue
+		}
+		infos[i].Outcome = OutcomeFailed
+		infos[i].Error = err.Error()
+	}
+	recordConversionInfos(infos...)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const testTemplateManifest = `apiVersion: template.openshift.io/v1
+kind: Template
+metadata:
+  name: web-template
+  resourceVersion: "42"
+parameters:
+- name: NAME
+- name: REPLICAS
+  value: "2"
+objects:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: ${NAME}
+  spec:
+    selector:
+      app: ${NAME}
+- apiVersion: apps.openshift.io/v1
+  kind: DeploymentConfig
+  metadata:
+    name: ${NAME}
+  spec:
+    replicas: ${{REPLICAS}}
+    selector:
+      app: ${NAME}
+      deploymentconfig: ${NAME}
+    strategy:
+      type: Rolling
+      rollingParams:
+        timeoutSeconds: ${{TIMEOUT}}
+    template:
+      metadata:
+        labels:
+          app: ${NAME}
+          deploymentconfig: ${NAME}
+      spec:
+        containers:
+        - name: web
+          image: ${IMAGE}
+`
+
+func TestIsParameterReference(t *testing.T) {
+	assert.True(t, isParameterReference("${REPLICAS}"))
+	assert.True(t, isParameterReference("${{REPLICAS}}"))
+	assert.True(t, isParameterReference("registry/${NAME}:latest"))
+	assert.False(t, isParameterReference("3"))
+}
+
+func TestConvertTemplate(t *testing.T) {
+	objects, err := decodeManifests(strings.NewReader(testTemplateManifest), "test")
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.True(t, isTemplate(objects[0]))
+
+	converted, infos, err := convertTemplate(objects[0], "test-namespace")
+	assert.NoError(t, err)
+	assert.Len(t, infos, 1)
+	assert.Equal(t, "${NAME}", infos[0].DeploymentConfigName)
+	assert.Equal(t, "web-template", infos[0].Template)
+	assert.Len(t, infos[0].findingsOf(codeValidationSkipped), 1)
+	assert.Empty(t, converted.GetResourceVersion())
+
+	convertedObjects, _, _ := unstructured.NestedSlice(converted.Object, "objects")
+	assert.Len(t, convertedObjects, 2)
+	assert.Equal(t, "Service", convertedObjects[0].(map[string]interface{})["kind"])
+
+	deployment := &unstructured.Unstructured{Object: convertedObjects[1].(map[string]interface{})}
+	assert.Equal(t, "Deployment", deployment.GetKind())
+	assert.Equal(t, "${NAME}", deployment.GetName())
+
+	replicas, _, _ := unstructured.NestedString(deployment.Object, "spec", "replicas")
+	assert.Equal(t, "${{REPLICAS}}", replicas)
+	deadline, _, _ := unstructured.NestedString(deployment.Object, "spec", "progressDeadlineSeconds")
+	assert.Equal(t, "${{TIMEOUT}}", deadline)
+	selector, _, _ := unstructured.NestedStringMap(deployment.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]string{"app": "${NAME}"}, selector)
+
+	// The source Template must not be modified.
+	sourceObjects, _, _ := unstructured.NestedSlice(objects[0].Object, "objects")
+	assert.Equal(t, "DeploymentConfig", sourceObjects[1].(map[string]interface{})["kind"])
+}
+
+func TestConvertTemplateSkipsFilteredDCs(t *testing.T) {
+	logFilePath = filepath.Join(t.TempDir(), "log.txt")
+	excludePatterns = []string{"*"}
+	defer func() { excludePatterns = nil }()
+
+	objects, err := decodeManifests(strings.NewReader(testTemplateManifest), "test")
+	assert.NoError(t, err)
+
+	converted, infos, err := convertTemplate(objects[0], "test-namespace")
+	assert.NoError(t, err)
+	assert.Len(t, infos, 1)
+	assert.Equal(t, OutcomeSkipped, infos[0].Outcome)
+	assert.Equal(t, "name matches --exclude *", infos[0].SkipReason)
+	assert.Equal(t, "web-template", infos[0].Template)
+
+	convertedObjects, _, _ := unstructured.NestedSlice(converted.Object, "objects")
+	assert.Len(t, convertedObjects, 2)
+	assert.Equal(t, "DeploymentConfig", convertedObjects[1].(map[string]interface{})["kind"])
+}
+
+func TestProcessTemplateOffline(t *testing.T) {
+	dir := t.TempDir()
+	out := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "template.yaml"), []byte(testTemplateManifest), 0600))
+
+	conversionInfos = nil
+	skippedInputObjects = nil
+	inputFiles = []string{dir}
+	outputDir = out
+	logFilePath = filepath.Join(out, "log.txt")
+	reportPath = filepath.Join(out, "report.pdf")
+	reportFormats = []string{"pdf"}
+	defer func() { inputFiles = nil }()
+
+	assert.NoError(t, runOfflineConverter(&cobra.Command{}))
+	assert.FileExists(t, filepath.Join(out, offlineDefaultNamespace, "templates", "web-template.yaml"))
+	assert.Len(t, conversionInfos, 1)
+	assert.Equal(t, OutcomeConverted, conversionInfos[0].Outcome)
+	assert.Empty(t, skippedInputObjects)
+}
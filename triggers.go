@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const imageTriggersAnnotation = "image.openshift.io/triggers"
+
+// imageChangeTrigger is the subset of a DeploymentConfig ImageChange trigger
+// needed to rebuild it as an image.openshift.io/triggers annotation entry.
+type imageChangeTrigger struct {
+	Index          int
+	ContainerNames []string
+	FromKind       string
+	FromName       string
+	FromNamespace  string
+	Automatic      bool
+}
+
+type imageTriggerFrom struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type imageTriggerAnnotationEntry struct {
+	From      imageTriggerFrom `json:"from"`
+	FieldPath string           `json:"fieldPath"`
+	Paused    string           `json:"paused,omitempty"`
+}
+
+func getImageChangeTriggers(dc *unstructured.Unstructured) ([]imageChangeTrigger, error) {
+	triggers, _, err := unstructured.NestedSlice(dc.Object, "spec", "triggers")
+	if err != nil {
+		return nil, fmt.Errorf("error getting triggers: %w", err)
+	}
+
+	var imageTriggers []imageChangeTrigger
+	for i, t := range triggers {
+		trigger, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if triggerType, _, _ := unstructured.NestedString(trigger, "type"); triggerType != "ImageChange" {
+			continue
+		}
+		params, found, err := unstructured.NestedMap(trigger, "imageChangeParams")
+		if err != nil {
+			return nil, fmt.Errorf("error getting imageChangeParams: %w", err)
+		}
+		if !found {
+			continue
+		}
+
+		containerNames, _, _ := unstructured.NestedStringSlice(params, "containerNames")
+		fromKind, _, _ := unstructured.NestedString(params, "from", "kind")
+		fromName, _, _ := unstructured.NestedString(params, "from", "name")
+		fromNamespace, _, _ := unstructured.NestedString(params, "from", "namespace")
+		automatic, _, _ := unstructured.NestedBool(params, "automatic")
+
+		if fromKind == "" {
+			fromKind = "ImageStreamTag"
+		}
+
+		imageTriggers = append(imageTriggers, imageChangeTrigger{
+			Index:          i,
+			ContainerNames: containerNames,
+			FromKind:       fromKind,
+			FromName:       fromName,
+			FromNamespace:  fromNamespace,
+			Automatic:      automatic,
+		})
+	}
+	return imageTriggers, nil
+}
+
+// setImageTriggers translates the ImageChange triggers of the DeploymentConfig
+// into the image.openshift.io/triggers annotation understood by the OpenShift
+// trigger controller. Non-automatic triggers are kept as paused entries.
+func setImageTriggers(dc, deployment *unstructured.Unstructured) error {
+	triggers, err := getImageChangeTriggers(dc)
+	if err != nil {
+		return err
+	}
+
+	var entries []imageTriggerAnnotationEntry
+	for _, trigger := range triggers {
+		if trigger.FromKind != "ImageStreamTag" || trigger.FromName == "" {
+			continue
+		}
+		for _, containerName := range trigger.ContainerNames {
+			entry := imageTriggerAnnotationEntry{
+				From: imageTriggerFrom{
+					Kind:      trigger.FromKind,
+					Name:      trigger.FromName,
+					Namespace: trigger.FromNamespace,
+				},
+				FieldPath: containerFieldPath(deployment, containerName),
+			}
+			if !trigger.Automatic {
+				entry.Paused = "true"
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("error marshaling image triggers: %w", err)
+	}
+
+	annotations := deployment.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[imageTriggersAnnotation] = string(data)
+	deployment.SetAnnotations(annotations)
+	return nil
+}
+
+// containerFieldPath returns the JSONPath of the image field of the named
+// container, pointing at initContainers when the container is declared there.
+func containerFieldPath(deployment *unstructured.Unstructured, containerName string) string {
+	field := "containers"
+	initContainers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "initContainers")
+	for _, c := range initContainers {
+		if container, ok := c.(map[string]interface{}); ok && container["name"] == containerName {
+			field = "initContainers"
+			break
+		}
+	}
+	return fmt.Sprintf("spec.template.spec.%s[?(@.name==%q)].image", field, containerName)
+}
+
+// manualImageTriggers lists the ImageChange triggers that cannot be carried
+// over as automatic redeploys and need a manual follow-up.
+func manualImageTriggers(dc *unstructured.Unstructured) []Finding {
+	triggers, err := getImageChangeTriggers(dc)
+	if err != nil {
+		return []Finding{newFinding(codeManualImageTrigger, "spec.triggers", fmt.Sprintf("Unable to read ImageChange triggers: %v", err))}
+	}
+
+	var followUps []Finding
+	for _, trigger := range triggers {
+		switch {
+		case trigger.FromKind != "ImageStreamTag" || trigger.FromName == "":
+			followUps = append(followUps, newFinding(codeManualImageTrigger, fmt.Sprintf("spec.triggers[%d].imageChangeParams.from", trigger.Index),
+				fmt.Sprintf("ImageChange trigger from %s %q is not supported by image.openshift.io/triggers and was dropped", trigger.FromKind, trigger.FromName)))
+		case !trigger.Automatic:
+			followUps = append(followUps, newFinding(codeManualImageTrigger, fmt.Sprintf("spec.triggers[%d].imageChangeParams.automatic", trigger.Index),
+				fmt.Sprintf("ImageChange trigger from %s %q is not automatic; it was added as paused and images must be rolled out manually", trigger.FromKind, trigger.FromName)))
+		}
+	}
+	return followUps
+}
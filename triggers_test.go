@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTriggeredDC(automatic bool, overrides ...func(*unstructured.Unstructured)) *unstructured.Unstructured {
+	return newTestDC(append([]func(*unstructured.Unstructured){
+		withoutField("spec", "template", "metadata"),
+		withField([]interface{}{
+			map[string]interface{}{"name": "init", "image": " "},
+		}, "spec", "template", "spec", "initContainers"),
+		withField([]interface{}{
+			map[string]interface{}{"name": "web", "image": " "},
+		}, "spec", "template", "spec", "containers"),
+		withField([]interface{}{
+			map[string]interface{}{"type": "ConfigChange"},
+			map[string]interface{}{
+				"type": "ImageChange",
+				"imageChangeParams": map[string]interface{}{
+					"automatic":      automatic,
+					"containerNames": []interface{}{"web", "init"},
+					"from": map[string]interface{}{
+						"kind":      "ImageStreamTag",
+						"name":      "web:latest",
+						"namespace": "images",
+					},
+				},
+			},
+		}, "spec", "triggers"),
+	}, overrides...)...)
+}
+
+func TestSetImageTriggers(t *testing.T) {
+	deployment, err := convertDCtoDeployment(newTriggeredDC(true), nil)
+	assert.NoError(t, err)
+
+	var entries []imageTriggerAnnotationEntry
+	assert.NoError(t, json.Unmarshal([]byte(deployment.GetAnnotations()[imageTriggersAnnotation]), &entries))
+	assert.Equal(t, []imageTriggerAnnotationEntry{
+		{
+			From:      imageTriggerFrom{Kind: "ImageStreamTag", Name: "web:latest", Namespace: "images"},
+			FieldPath: `spec.template.spec.containers[?(@.name=="web")].image`,
+		},
+		{
+			From:      imageTriggerFrom{Kind: "ImageStreamTag", Name: "web:latest", Namespace: "images"},
+			FieldPath: `spec.template.spec.initContainers[?(@.name=="init")].image`,
+		},
+	}, entries)
+
+	_, found, _ := unstructured.NestedSlice(deployment.Object, "spec", "triggers")
+	assert.False(t, found)
+}
+
+func TestSetImageTriggersPausesManualTriggers(t *testing.T) {
+	deployment, err := convertDCtoDeployment(newTriggeredDC(false), nil)
+	assert.NoError(t, err)
+
+	var entries []imageTriggerAnnotationEntry
+	assert.NoError(t, json.Unmarshal([]byte(deployment.GetAnnotations()[imageTriggersAnnotation]), &entries))
+	assert.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, "true", entry.Paused)
+	}
+}
+
+func TestManualImageTriggers(t *testing.T) {
+	assert.Empty(t, manualImageTriggers(newTriggeredDC(true)))
+	assert.Len(t, manualImageTriggers(newTriggeredDC(false)), 1)
+}
@@ -1,16 +1,122 @@
 package main
 
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	generatedByAnnotation        = "openshift.io/generated-by"
+	generatedByValue             = "deploymentconfig-to-deployment-migration"
+	migrationTimestampAnnotation = "openshift.io/migration-timestamp"
+	migrationRunIDAnnotation     = "openshift.io/migration-run-id"
+
+	// fieldManager owns the fields of the Deployments applied by the tool.
+	fieldManager = "openshift-dc-migration"
+)
+
+// ApplyOutcome is the result of server-side applying a Deployment.
+type ApplyOutcome string
+
+const (
+	ApplyCreated    ApplyOutcome = "created"
+	ApplyUpdated    ApplyOutcome = "updated"
+	ApplyUnchanged  ApplyOutcome = "unchanged"
+	ApplyConflicted ApplyOutcome = "conflicted"
+)
+
+// Outcome is what became of a DeploymentConfig in a run.
+type Outcome string
+
+const (
+	OutcomeConverted Outcome = "converted"
+	OutcomeApplied   Outcome = "applied"
+	OutcomeFailed    Outcome = "failed"
+	OutcomeSkipped   Outcome = "skipped"
+	OutcomePanicked  Outcome = "panicked"
+)
+
+// outcomes lists the outcomes in the order the reports show them.
+var outcomes = []Outcome{OutcomeConverted, OutcomeApplied, OutcomeFailed, OutcomeSkipped, OutcomePanicked}
+
+// succeeded reports whether the outcome counts as a successful migration.
+func (o Outcome) succeeded() bool {
+	return o == OutcomeConverted || o == OutcomeApplied
+}
+
+// failed reports whether the DeploymentConfig failed or panicked.
+func (o Outcome) failed() bool {
+	return o == OutcomeFailed || o == OutcomePanicked
+}
+
+// migrationRunID identifies the objects generated by this run so that the
+// rollback command can target them.
+var migrationRunID = time.Now().UTC().Format("20060102-150405")
+
+// ConversionInfo records the conversion of a DeploymentConfig. Everything
+// the detectors and converter stages noticed about it is in Findings.
 type ConversionInfo struct {
-	Timestamp            string
-	Namespace            string
-	DeploymentConfigName string
-	HasTriggers          bool
-	HasLifecycleHooks    bool
-	HasAutoRollbacks     bool
-	UsesCustomStrategies bool
+	Timestamp            string             `json:"timestamp"`
+	Namespace            string             `json:"namespace"`
+	DeploymentConfigName string             `json:"deploymentConfig"`
+	Template             string             `json:"template,omitempty"`
+	Outcome              Outcome            `json:"outcome"`
+	Error                string             `json:"error,omitempty"`
+	Findings             []Finding          `json:"findings"`
+	ImageResolutions     []ImageResolution  `json:"imageResolutions,omitempty"`
+	LifecycleHooks       []HookSummary      `json:"lifecycleHooks,omitempty"`
+	DependentRewrites    []DependentRewrite `json:"dependentRewrites,omitempty"`
+	CutoverSteps         []CutoverStep      `json:"cutoverSteps,omitempty"`
+	ApplyOutcome         ApplyOutcome       `json:"applyOutcome,omitempty"`
+	Validated            bool               `json:"validated"`
+	// SourceManifest and DeploymentManifest are the DeploymentConfig and the
+	// generated Deployment as YAML, cleaned of server managed fields.
+	SourceManifest     string `json:"sourceManifest,omitempty"`
+	DeploymentManifest string `json:"deploymentManifest,omitempty"`
+	// SkipReason is set when the DeploymentConfig was left out of the
+	// migration; the other fields are then empty.
+	SkipReason string `json:"skipReason,omitempty"`
+}
+
+var (
+	conversionInfos   []ConversionInfo
+	conversionInfosMu sync.Mutex
+)
+
+// recordConversionInfos logs the findings of the infos and adds them to
+// conversionInfos; it is safe for concurrent use.
+func recordConversionInfos(infos ...ConversionInfo) {
+	for _, info := range infos {
+		logFindings(info)
+	}
+
+	conversionInfosMu.Lock()
+	defer conversionInfosMu.Unlock()
+	conversionInfos = append(conversionInfos, infos...)
+}
+
+// sortConversionInfos orders conversionInfos by namespace, Template and
+// DeploymentConfig name so that the report does not depend on the order in
+// which concurrent workers finished.
+func sortConversionInfos() {
+	conversionInfosMu.Lock()
+	defer conversionInfosMu.Unlock()
+	sort.SliceStable(conversionInfos, func(i, j int) bool {
+		a, b := conversionInfos[i], conversionInfos[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Template != b.Template {
+			return a.Template < b.Template
+		}
+		return a.DeploymentConfigName < b.DeploymentConfigName
+	})
 }
 
-var conversionInfos []ConversionInfo
+// skippedInputObjects lists the non-DeploymentConfig objects found in the
+// --from-file inputs.
+var skippedInputObjects []string
 
 var (
 	dcSpecificLabels = []string{
@@ -6,18 +6,33 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/flowcontrol"
 	"sigs.k8s.io/yaml"
 )
 
+var (
+	deploymentConfigRes = schema.GroupVersionResource{Group: "apps.openshift.io", Version: "v1", Resource: "deploymentconfigs"}
+	deploymentRes       = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+)
+
+// logMu serializes writes to the log file.
+var logMu sync.Mutex
+
 func logMessage(message string) error {
+	logMu.Lock()
+	defer logMu.Unlock()
+
 	f, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
 	if err != nil {
 		return fmt.Errorf("error opening log file: %w", err)
@@ -28,7 +43,9 @@ func logMessage(message string) error {
 	return err
 }
 
-func validateProjects(client dynamic.Interface, projects []string) ([]string, error) {
+// validateProjects returns the projects that are not reserved and exist,
+// logging a warning for every other one.
+func validateProjects(client dynamic.Interface, projects []string) []string {
 	var validProjects []string
 	ctx := context.Background()
 	for _, project := range projects {
@@ -41,7 +58,7 @@ func validateProjects(client dynamic.Interface, projects []string) ([]string, er
 			continue
 		}
 
-		_, err := client.Resource(schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}).Get(ctx, project, metav1.GetOptions{})
+		_, err := client.Resource(namespaceRes).Get(ctx, project, metav1.GetOptions{})
 		if err != nil {
 			err := logMessage(fmt.Sprintf("Warning: Project %s not found or not accessible: %v", project, err))
 			if err != nil {
@@ -51,10 +68,7 @@ func validateProjects(client dynamic.Interface, projects []string) ([]string, er
 		}
 		validProjects = append(validProjects, project)
 	}
-	if len(validProjects) == 0 {
-		return nil, fmt.Errorf("no valid projects found among the provided projects")
-	}
-	return validProjects, nil
+	return validProjects
 }
 
 func isReservedNamespace(namespace string) bool {
@@ -69,35 +83,145 @@ func isReservedNamespace(namespace string) bool {
 	return false
 }
 
-func getDCs(client dynamic.Interface, namespace string) (*unstructured.UnstructuredList, error) {
+// maxListRestarts bounds how often getDCs restarts a listing whose continue
+// token expired.
+const maxListRestarts = 3
+
+// getDCs lists the DeploymentConfigs of a namespace in pages of pageSize and
+// calls handle with each page, so that a namespace is never held in memory at
+// once. When the continue token expires (410 Gone) the listing restarts from
+// the beginning and skips the DeploymentConfigs already handled.
+func getDCs(client dynamic.Interface, namespace string, handle func(dcs []unstructured.Unstructured) error) error {
 	ctx := context.Background()
-	dcRes := schema.GroupVersionResource{Group: "apps.openshift.io", Version: "v1", Resource: "deploymentconfigs"}
-	return client.Resource(dcRes).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	handled := map[string]bool{}
+	restarts := 0
+	opts := metav1.ListOptions{Limit: pageSize}
+	for {
+		list, err := client.Resource(deploymentConfigRes).Namespace(namespace).List(ctx, opts)
+		if (apierrors.IsResourceExpired(err) || apierrors.IsGone(err)) && restarts < maxListRestarts {
+			restarts++
+			logErr := logMessage(fmt.Sprintf("Continue token expired while listing DeploymentConfigs in project %s, restarting the list", namespace))
+			if logErr != nil {
+				fmt.Printf("Failed to log message: %v\n", logErr)
+			}
+			opts.Continue = ""
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error listing DeploymentConfigs: %w", err)
+		}
+
+		var page []unstructured.Unstructured
+		for _, dc := range list.Items {
+			if handled[dc.GetName()] {
+				continue
+			}
+			handled[dc.GetName()] = true
+			page = append(page, dc)
+		}
+		if len(page) > 0 {
+			if err := handle(page); err != nil {
+				return err
+			}
+		}
+
+		if list.GetContinue() == "" {
+			return nil
+		}
+		opts.Continue = list.GetContinue()
+	}
 }
 
-func saveDeploymentYAML(deployment *unstructured.Unstructured, namespace string) error {
-	data, err := yaml.Marshal(deployment)
+func saveManifestYAML(manifest *unstructured.Unstructured, namespace string) error {
+	return writeManifestYAML(manifest, filepath.Join(outputDir, namespace), manifest.GetName())
+}
+
+// manifestYAML renders the object as YAML without its server managed fields,
+// or returns an empty string if it cannot be marshaled.
+func manifestYAML(obj *unstructured.Unstructured) string {
+	manifest := obj.DeepCopy()
+	cleanServerFields(manifest)
+	data, err := yaml.Marshal(manifest)
 	if err != nil {
-		return fmt.Errorf("error marshaling deployment to YAML: %w", err)
+		return ""
+	}
+	return string(data)
+}
+
+func writeManifestYAML(manifest *unstructured.Unstructured, dir, name string) error {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("error marshaling %s to YAML: %w", manifest.GetKind(), err)
 	}
 
-	dir := filepath.Join(outputDir, namespace)
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return fmt.Errorf("error creating output directory: %w", err)
 	}
 
-	filename := filepath.Join(dir, fmt.Sprintf("%s.yaml", deployment.GetName()))
+	filename := filepath.Join(dir, fmt.Sprintf("%s.yaml", name))
 	return os.WriteFile(filename, data, 0600)
 }
 
-func applyDeployment(client dynamic.Interface, deployment *unstructured.Unstructured) error {
+// cleanServerFields removes the fields managed by the API server so that the
+// object can be re-applied as is.
+func cleanServerFields(obj *unstructured.Unstructured) {
+	for _, field := range []string{"resourceVersion", "uid", "creationTimestamp", "generation", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "status")
+}
+
+// applyDeployment server-side applies the Deployment as fieldManager. A
+// Deployment created by an earlier run keeps that run's tracking annotations so
+// that applying an unchanged conversion leaves it untouched. Conflicts with
+// fields owned by other managers fail the apply unless forceConflicts is set.
+func applyDeployment(client dynamic.Interface, deployment *unstructured.Unstructured) (ApplyOutcome, error) {
 	ctx := context.Background()
-	deploymentRes := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
-	_, err := client.Resource(deploymentRes).Namespace(deployment.GetNamespace()).Create(ctx, deployment, metav1.CreateOptions{})
+	resource := client.Resource(deploymentRes).Namespace(deployment.GetNamespace())
+
+	existing, err := resource.Get(ctx, deployment.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("error getting deployment %s in namespace %s: %w", deployment.GetName(), deployment.GetNamespace(), err)
+	}
+	if err == nil {
+		preserveTrackingAnnotations(existing, deployment)
+	}
+
+	applied, err := resource.Apply(ctx, deployment.GetName(), deployment, metav1.ApplyOptions{FieldManager: fieldManager, Force: forceConflicts})
 	if err != nil {
-		return fmt.Errorf("error applying deployment %s in namespace %s: %w", deployment.GetName(), deployment.GetNamespace(), err)
+		if apierrors.IsConflict(err) {
+			return ApplyConflicted, fmt.Errorf("conflict applying deployment %s in namespace %s (rerun with --force-conflicts to take ownership): %w", deployment.GetName(), deployment.GetNamespace(), err)
+		}
+		return "", fmt.Errorf("error applying deployment %s in namespace %s: %w", deployment.GetName(), deployment.GetNamespace(), err)
+	}
+
+	switch {
+	case existing == nil:
+		return ApplyCreated, nil
+	case applied.GetResourceVersion() == existing.GetResourceVersion():
+		return ApplyUnchanged, nil
+	default:
+		return ApplyUpdated, nil
 	}
-	return nil
+}
+
+// preserveTrackingAnnotations copies the migration timestamp and run ID of a
+// Deployment generated by an earlier run to its new conversion.
+func preserveTrackingAnnotations(existing, deployment *unstructured.Unstructured) {
+	existingAnnotations := existing.GetAnnotations()
+	if existingAnnotations[generatedByAnnotation] != generatedByValue {
+		return
+	}
+	annotations := deployment.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for _, key := range []string{migrationTimestampAnnotation, migrationRunIDAnnotation} {
+		if value, ok := existingAnnotations[key]; ok {
+			annotations[key] = value
+		}
+	}
+	deployment.SetAnnotations(annotations)
 }
 
 func hasTriggers(dc *unstructured.Unstructured) bool {
@@ -106,10 +230,7 @@ func hasTriggers(dc *unstructured.Unstructured) bool {
 }
 
 func hasLifecycleHooks(dc *unstructured.Unstructured) bool {
-	_, preHookFound, _ := unstructured.NestedMap(dc.Object, "spec", "strategy", "recreateParams", "pre")
-	_, midHookFound, _ := unstructured.NestedMap(dc.Object, "spec", "strategy", "recreateParams", "mid")
-	_, postHookFound, _ := unstructured.NestedMap(dc.Object, "spec", "strategy", "recreateParams", "post")
-	return preHookFound || midHookFound || postHookFound
+	return len(getLifecycleHooks(dc)) > 0
 }
 
 func hasAutoRollbacks(dc *unstructured.Unstructured) bool {
@@ -131,6 +252,60 @@ func contains(slice []string, s string) bool {
 	return false
 }
 
+// forEachConcurrently calls fn for every index below n, running at most
+// workers calls at a time, and returns once all calls returned.
+func forEachConcurrently(n, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// connectToCluster builds a dynamic client from the kubeconfig after checking
+// that the cluster is reachable.
+func connectToCluster() (dynamic.Interface, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("error building kubeconfig: %w", err)
+	}
+	config.QPS = clientQPS
+	config.Burst = clientBurst
+	// One rate limiter shared by every client keeps the total within --qps
+	// and --burst.
+	config.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(clientQPS, clientBurst)
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Kubernetes clientset: %w", err)
+	}
+
+	// Perform preflight check
+	if err := preflightCheck(clientset); err != nil {
+		return nil, fmt.Errorf("preflight check failed: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating dynamic client: %w", err)
+	}
+	validationClient, err = newValidationClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating validation client: %w", err)
+	}
+	return dynamicClient, nil
+}
+
 func preflightCheck(clientset *kubernetes.Clientset) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
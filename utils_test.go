@@ -1,10 +1,23 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestIsReservedNamespace(t *testing.T) {
@@ -64,6 +77,18 @@ func TestHasLifecycleHooks(t *testing.T) {
 		},
 	}
 
+	dcWithRollingHooks := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"strategy": map[string]interface{}{
+					"rollingParams": map[string]interface{}{
+						"post": map[string]interface{}{},
+					},
+				},
+			},
+		},
+	}
+
 	dcWithoutHooks := &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"spec": map[string]interface{}{
@@ -73,6 +98,7 @@ func TestHasLifecycleHooks(t *testing.T) {
 	}
 
 	assert.True(t, hasLifecycleHooks(dcWithHooks))
+	assert.True(t, hasLifecycleHooks(dcWithRollingHooks))
 	assert.False(t, hasLifecycleHooks(dcWithoutHooks))
 }
 
@@ -129,3 +155,222 @@ func TestUsesCustomStrategies(t *testing.T) {
 }
 
 // Add more tests for other functions in utils.go
+
+// newTestDC returns a DeploymentConfig test-dc in test-namespace that selects
+// app=test-app and runs a single web container, with the overrides applied.
+func newTestDC(overrides ...func(*unstructured.Unstructured)) *unstructured.Unstructured {
+	dc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps.openshift.io/v1",
+		"kind":       "DeploymentConfig",
+		"metadata":   map[string]interface{}{"name": "test-dc", "namespace": "test-namespace"},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{"app": "test-app"},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": "test-app"},
+				},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "web", "image": "quay.io/example/web:1.0"},
+					},
+				},
+			},
+		},
+	}}
+	for _, override := range overrides {
+		override(dc)
+	}
+	return dc
+}
+
+// withField sets a field of the object built by newTestDC.
+func withField(value interface{}, fields ...string) func(*unstructured.Unstructured) {
+	return func(obj *unstructured.Unstructured) {
+		if err := unstructured.SetNestedField(obj.Object, value, fields...); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// withoutField removes a field of the object built by newTestDC.
+func withoutField(fields ...string) func(*unstructured.Unstructured) {
+	return func(obj *unstructured.Unstructured) {
+		unstructured.RemoveNestedField(obj.Object, fields...)
+	}
+}
+
+// addApplyReactor emulates server-side apply, which the fake client only
+// supports for typed objects: the applied object replaces the stored one and
+// the resourceVersion is bumped when anything changed.
+func addApplyReactor(client *dynamicfake.FakeDynamicClient, gvr schema.GroupVersionResource) {
+	client.PrependReactor("patch", gvr.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(k8stesting.PatchAction)
+		if patchAction.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patchAction.GetPatch()); err != nil {
+			return true, nil, err
+		}
+
+		tracker := client.Tracker()
+		existing, err := tracker.Get(gvr, patchAction.GetNamespace(), patchAction.GetName())
+		if apierrors.IsNotFound(err) {
+			obj.SetResourceVersion("1")
+			return true, obj, tracker.Create(gvr, obj, patchAction.GetNamespace())
+		}
+		if err != nil {
+			return true, nil, err
+		}
+
+		current := existing.(*unstructured.Unstructured)
+		obj.SetResourceVersion(current.GetResourceVersion())
+		if reflect.DeepEqual(current.Object, obj.Object) {
+			return true, current, nil
+		}
+		version, _ := strconv.Atoi(current.GetResourceVersion())
+		obj.SetResourceVersion(strconv.Itoa(version + 1))
+		return true, obj, tracker.Update(gvr, obj, patchAction.GetNamespace())
+	})
+}
+
+func TestApplyDeployment(t *testing.T) {
+	forceConflicts = false
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		deploymentRes: "DeploymentList",
+	})
+	addApplyReactor(client, deploymentRes)
+
+	deployment, err := convertDCtoDeployment(newHookedDC(), nil)
+	assert.NoError(t, err)
+	outcome, err := applyDeployment(client, deployment.DeepCopy())
+	assert.NoError(t, err)
+	assert.Equal(t, ApplyCreated, outcome)
+
+	// A later run keeps the tracking annotations of the run that created it.
+	rerun := deployment.DeepCopy()
+	annotations := rerun.GetAnnotations()
+	annotations[migrationRunIDAnnotation] = "later-run"
+	annotations[migrationTimestampAnnotation] = "2030-01-01T00:00:00Z"
+	rerun.SetAnnotations(annotations)
+	outcome, err = applyDeployment(client, rerun)
+	assert.NoError(t, err)
+	assert.Equal(t, ApplyUnchanged, outcome)
+	assert.Equal(t, deployment.GetAnnotations()[migrationRunIDAnnotation], rerun.GetAnnotations()[migrationRunIDAnnotation])
+
+	changed := deployment.DeepCopy()
+	assert.NoError(t, unstructured.SetNestedField(changed.Object, int64(5), "spec", "replicas"))
+	outcome, err = applyDeployment(client, changed)
+	assert.NoError(t, err)
+	assert.Equal(t, ApplyUpdated, outcome)
+
+	live, err := client.Resource(deploymentRes).Namespace("test-namespace").Get(context.Background(), "test-dc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	replicas, _, _ := unstructured.NestedInt64(live.Object, "spec", "replicas")
+	assert.Equal(t, int64(5), replicas)
+
+	client.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewApplyConflict([]metav1.StatusCause{
+			{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "argocd-controller"`, Field: ".spec.replicas"},
+		}, `Apply failed with 1 conflict: conflict with "argocd-controller": .spec.replicas`)
+	})
+	outcome, err = applyDeployment(client, deployment.DeepCopy())
+	assert.ErrorContains(t, err, "--force-conflicts")
+	assert.Equal(t, ApplyConflicted, outcome)
+}
+
+func TestForEachConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	called := make([]bool, 20)
+
+	forEachConcurrently(len(called), 3, func(i int) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		called[i] = true
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+
+	assert.LessOrEqual(t, maxRunning, 3)
+	for _, c := range called {
+		assert.True(t, c)
+	}
+}
+
+func TestGetDCsRestartsExpiredList(t *testing.T) {
+	logFilePath = filepath.Join(t.TempDir(), "log.txt")
+
+	var dcs []unstructured.Unstructured
+	for i := 0; i < 5; i++ {
+		dc := newHookedDC()
+		dc.SetName(fmt.Sprintf("dc-%d", i))
+		dcs = append(dcs, *dc)
+	}
+	page := func(start, end int) *unstructured.UnstructuredList {
+		list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "apps.openshift.io/v1", "kind": "DeploymentConfigList"}}
+		if end < len(dcs) {
+			list.SetContinue(strconv.Itoa(end))
+		}
+		list.Items = append(list.Items, dcs[start:end]...)
+		return list
+	}
+
+	// The fake client drops the limit and continue token of list requests, so
+	// the responses of the server are scripted: the continue token of the
+	// second page expires once, which restarts the list from the first page.
+	responses := []func() (runtime.Object, error){
+		func() (runtime.Object, error) { return page(0, 2), nil },
+		func() (runtime.Object, error) { return nil, apierrors.NewResourceExpired("continue token expired") },
+		func() (runtime.Object, error) { return page(0, 2), nil },
+		func() (runtime.Object, error) { return page(2, 4), nil },
+		func() (runtime.Object, error) { return page(4, 5), nil },
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		deploymentConfigRes: "DeploymentConfigList",
+	})
+	calls := 0
+	client.PrependReactor("list", "deploymentconfigs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj, err := responses[calls]()
+		calls++
+		return true, obj, err
+	})
+
+	var handled [][]string
+	err := getDCs(client, "test-namespace", func(page []unstructured.Unstructured) error {
+		var names []string
+		for _, dc := range page {
+			names = append(names, dc.GetName())
+		}
+		handled = append(handled, names)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, len(responses), calls)
+	// The DCs listed again after the restart are not handled twice.
+	assert.Equal(t, [][]string{{"dc-0", "dc-1"}, {"dc-2", "dc-3"}, {"dc-4"}}, handled)
+}
+
+func TestGetDCsGivesUpAfterRestarts(t *testing.T) {
+	logFilePath = filepath.Join(t.TempDir(), "log.txt")
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		deploymentConfigRes: "DeploymentConfigList",
+	})
+	calls := 0
+	client.PrependReactor("list", "deploymentconfigs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls++
+		return true, nil, apierrors.NewResourceExpired("continue token expired")
+	})
+
+	err := getDCs(client, "test-namespace", func([]unstructured.Unstructured) error { return nil })
+	assert.True(t, apierrors.IsResourceExpired(err))
+	assert.Equal(t, maxListRestarts+1, calls)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// validationClient is the dynamic client built by connectToCluster for
+// --validate=server. It shares the rate limiter of the other clients.
+var validationClient dynamic.Interface
+
+// warningRecorder collects the warnings returned by the API server, such as
+// Pod Security admission warnings.
+type warningRecorder struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (r *warningRecorder) HandleWarningHeader(code int, agent string, text string) {
+	if code != 299 || text == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warnings = append(r.warnings, text)
+}
+
+func (r *warningRecorder) Warnings() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.warnings...)
+}
+
+type warningRecorderKey struct{}
+
+// withWarningRecorder returns a context whose requests record their warnings
+// in recorder.
+func withWarningRecorder(ctx context.Context, recorder *warningRecorder) context.Context {
+	return context.WithValue(ctx, warningRecorderKey{}, recorder)
+}
+
+// warningTransport hands the warnings of each response to the recorder of
+// the request context, so that concurrent requests sharing a client are
+// attributed to the right Deployment.
+type warningTransport struct {
+	next http.RoundTripper
+}
+
+func (t *warningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	recorder, ok := req.Context().Value(warningRecorderKey{}).(*warningRecorder)
+	if err != nil || !ok {
+		return resp, err
+	}
+	warnings, _ := utilnet.ParseWarningHeaders(resp.Header["Warning"])
+	for _, warning := range warnings {
+		recorder.HandleWarningHeader(warning.Code, warning.Agent, warning.Text)
+	}
+	return resp, nil
+}
+
+// newValidationClient builds the client used by validateOnServer. The
+// warnings are recorded per request instead of being logged.
+func newValidationClient(config *rest.Config) (dynamic.Interface, error) {
+	config = rest.CopyConfig(config)
+	config.WarningHandler = rest.NoWarnings{}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &warningTransport{next: rt}
+	})
+	return dynamic.NewForConfig(config)
+}
+
+// validateOnServer submits the Deployment to the API server as a dry-run so
+// that it goes through defaulting, validation and admission without being
+// persisted. It returns the admission errors and warnings.
+func validateOnServer(deployment *unstructured.Unstructured) []Finding {
+	return dryRunDeployment(validationClient, &warningRecorder{}, deployment)
+}
+
+func dryRunDeployment(client dynamic.Interface, recorder *warningRecorder, deployment *unstructured.Unstructured) []Finding {
+	ctx := withWarningRecorder(context.Background(), recorder)
+	_, err := client.Resource(deploymentRes).Namespace(deployment.GetNamespace()).Apply(ctx, deployment.GetName(), deployment, metav1.ApplyOptions{
+		FieldManager: fieldManager,
+		Force:        forceConflicts,
+		DryRun:       []string{metav1.DryRunAll},
+	})
+	var findings []Finding
+	if err != nil {
+		findings = append(findings, newFinding(codeAdmissionError, "spec", err.Error()))
+	}
+	// Admission warnings do not say which field they are about; most come
+	// from policies on the pod template.
+	for _, warning := range recorder.Warnings() {
+		findings = append(findings, newFinding(codeAdmissionWarning, "spec.template.spec", warning))
+	}
+	return findings
+}
+
+// validateDeployment strictly decodes the Deployment into the apps/v1 type,
+// rejecting unknown fields and mistyped values, and runs the semantic checks
+// the API server would otherwise only report at apply time.
+func validateDeployment(deployment *unstructured.Unstructured) []Finding {
+	typed := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(deployment.Object, typed, true); err != nil {
+		return []Finding{newFinding(codeInvalidDeployment, "spec", fmt.Sprintf("does not match the apps/v1 Deployment schema: %v", err))}
+	}
+
+	var problems []Finding
+	problem := func(field, message string) {
+		problems = append(problems, newFinding(codeInvalidDeployment, field, message))
+	}
+	spec := typed.Spec
+	if spec.Replicas != nil && *spec.Replicas < 0 {
+		problem("spec.replicas", fmt.Sprintf("spec.replicas is negative: %d", *spec.Replicas))
+	}
+
+	if spec.Selector == nil || (len(spec.Selector.MatchLabels) == 0 && len(spec.Selector.MatchExpressions) == 0) {
+		problem("spec.selector", "spec.selector is empty")
+	} else if selector, err := metav1.LabelSelectorAsSelector(spec.Selector); err != nil {
+		problem("spec.selector", fmt.Sprintf("spec.selector is invalid: %v", err))
+	} else if !selector.Matches(labels.Set(spec.Template.Labels)) {
+		problem("spec.selector", fmt.Sprintf("spec.selector %s does not match spec.template.metadata.labels", selector))
+	}
+
+	if len(spec.Template.Spec.Containers) == 0 {
+		problem("spec.template.spec.containers", "spec.template.spec.containers is empty")
+	}
+	names := map[string]bool{}
+	for _, field := range []string{"initContainers", "containers"} {
+		containers := spec.Template.Spec.Containers
+		if field == "initContainers" {
+			containers = spec.Template.Spec.InitContainers
+		}
+		for i, container := range containers {
+			if container.Name == "" {
+				problem(fmt.Sprintf("spec.template.spec.%s[%d].name", field, i), "a container has no name")
+				continue
+			}
+			if names[container.Name] {
+				problem(fmt.Sprintf("spec.template.spec.%s[name=%s]", field, container.Name), fmt.Sprintf("container name %q is not unique", container.Name))
+			}
+			names[container.Name] = true
+		}
+	}
+
+	switch spec.Strategy.Type {
+	case appsv1.RecreateDeploymentStrategyType:
+		if spec.Strategy.RollingUpdate != nil {
+			problem("spec.strategy.rollingUpdate", "spec.strategy.rollingUpdate must not be set when the type is Recreate")
+		}
+	case appsv1.RollingUpdateDeploymentStrategyType, "":
+		if rollingUpdate := spec.Strategy.RollingUpdate; rollingUpdate != nil && isZeroIntOrPercent(rollingUpdate.MaxSurge) && isZeroIntOrPercent(rollingUpdate.MaxUnavailable) {
+			problem("spec.strategy.rollingUpdate", "spec.strategy.rollingUpdate.maxSurge and maxUnavailable must not both be 0")
+		}
+	default:
+		problem("spec.strategy.type", fmt.Sprintf("spec.strategy.type %q is neither Recreate nor RollingUpdate", spec.Strategy.Type))
+	}
+
+	return problems
+}
+
+// isZeroIntOrPercent reports whether a set maxSurge or maxUnavailable value
+// is 0 or 0%.
+func isZeroIntOrPercent(value *intstr.IntOrString) bool {
+	if value == nil {
+		return false
+	}
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(value, 100, true)
+	return err == nil && scaled == 0
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDryRunDeployment(t *testing.T) {
+	deployment, err := convertDCtoDeployment(newHookedDC(), nil)
+	assert.NoError(t, err)
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		deploymentRes: "DeploymentList",
+	})
+	recorder := &warningRecorder{}
+	client.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		recorder.HandleWarningHeader(299, "-", `would violate PodSecurity "restricted:latest": allowPrivilegeEscalation != false`)
+		recorder.HandleWarningHeader(199, "-", "ignored")
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "test-dc", assert.AnError)
+	})
+
+	findings := dryRunDeployment(client, recorder, deployment)
+	assert.Len(t, findings, 2)
+	assert.Equal(t, codeAdmissionError, findings[0].Code)
+	assert.Contains(t, findings[0].Message, "forbidden")
+	assert.Equal(t, newFinding(codeAdmissionWarning, "spec.template.spec", `would violate PodSecurity "restricted:latest": allowPrivilegeEscalation != false`), findings[1])
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestWarningTransportAttributesPerRequest(t *testing.T) {
+	transport := &warningTransport{next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Add("Warning", `299 - "warning for `+req.URL.Path+`"`)
+		header.Add("Warning", `199 - "ignored"`)
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: http.NoBody}, nil
+	})}
+
+	first, second := &warningRecorder{}, &warningRecorder{}
+	for path, recorder := range map[string]*warningRecorder{"/first": first, "/second": second, "/none": nil} {
+		ctx := context.Background()
+		if recorder != nil {
+			ctx = withWarningRecorder(ctx, recorder)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPatch, "https://cluster"+path, nil)
+		assert.NoError(t, err)
+		_, err = transport.RoundTrip(req)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"warning for /first"}, first.Warnings())
+	assert.Equal(t, []string{"warning for /second"}, second.Warnings())
+}
+
+func TestValidateDeployment(t *testing.T) {
+	valid, err := convertDCtoDeployment(newHookedDC(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, validateDeployment(valid))
+
+	tests := []struct {
+		name     string
+		mutate   func(obj map[string]interface{})
+		expected string
+	}{
+		{"String replicas", func(obj map[string]interface{}) {
+			_ = unstructured.SetNestedField(obj, "3", "spec", "replicas")
+		}, "does not match the apps/v1 Deployment schema"},
+		{"Unknown field", func(obj map[string]interface{}) {
+			_ = unstructured.SetNestedField(obj, int64(1), "spec", "triggers")
+		}, "unknown field"},
+		{"Selector not matching template", func(obj map[string]interface{}) {
+			_ = unstructured.SetNestedStringMap(obj, map[string]string{"app": "other"}, "spec", "selector", "matchLabels")
+		}, "does not match spec.template.metadata.labels"},
+		{"Empty selector", func(obj map[string]interface{}) {
+			_ = unstructured.SetNestedMap(obj, map[string]interface{}{}, "spec", "selector")
+		}, "spec.selector is empty"},
+		{"Duplicate container names", func(obj map[string]interface{}) {
+			containers, _, _ := unstructured.NestedSlice(obj, "spec", "template", "spec", "containers")
+			_ = unstructured.SetNestedSlice(obj, append(containers, containers[0]), "spec", "template", "spec", "containers")
+		}, "is not unique"},
+		{"Rolling parameters on Recreate", func(obj map[string]interface{}) {
+			_ = unstructured.SetNestedMap(obj, map[string]interface{}{
+				"type":          "Recreate",
+				"rollingUpdate": map[string]interface{}{"maxSurge": int64(1)},
+			}, "spec", "strategy")
+		}, "must not be set when the type is Recreate"},
+		{"No surge and no unavailability", func(obj map[string]interface{}) {
+			_ = unstructured.SetNestedMap(obj, map[string]interface{}{
+				"type":          "RollingUpdate",
+				"rollingUpdate": map[string]interface{}{"maxSurge": "0%", "maxUnavailable": int64(0)},
+			}, "spec", "strategy")
+		}, "must not both be 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := valid.DeepCopy()
+			tt.mutate(deployment.Object)
+			var messages []string
+			for _, problem := range validateDeployment(deployment) {
+				assert.Equal(t, codeInvalidDeployment, problem.Code)
+				messages = append(messages, problem.Message)
+			}
+			assert.NotEmpty(t, messages)
+			assert.Contains(t, strings.Join(messages, "\n"), tt.expected)
+		})
+	}
+}